@@ -2,14 +2,20 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/urfave/cli"
 
 	"code/crawler"
 	"code/internal/limiter"
+	"code/internal/scope"
+	"code/internal/urlutil"
 )
 
 // Run executes the CLI and writes the JSON report to stdout.
@@ -27,6 +33,18 @@ func Run(args []string, stdout, stderr io.Writer, client *http.Client, clock lim
 			Usage: "crawl depth",
 			Value: 10,
 		},
+		cli.IntFlag{
+			Name:  "max-pages",
+			Usage: "stop queuing pages after N pages (0 = unlimited)",
+		},
+		cli.DurationFlag{
+			Name:  "max-duration",
+			Usage: "stop queuing pages after this long, e.g. 10m (0 = unlimited)",
+		},
+		cli.IntFlag{
+			Name:  "max-urls-per-prefix",
+			Usage: "queue at most N pages from one directory, e.g. /products/ (0 = unlimited)",
+		},
 		cli.IntFlag{
 			Name:  "retries",
 			Usage: "number of retries for failed requests",
@@ -46,6 +64,63 @@ func Run(args []string, stdout, stderr io.Writer, client *http.Client, clock lim
 			Name:  "rps",
 			Usage: "limit requests per second (overrides delay)",
 		},
+		cli.Float64Flag{
+			Name:  "per-host-rps",
+			Usage: "limit requests per second to each host",
+		},
+		cli.IntFlag{
+			Name:  "burst",
+			Usage: "allow bursts of up to N requests (token bucket)",
+		},
+		cli.BoolFlag{
+			Name:  "adaptive",
+			Usage: "back off on 429/503 and latency spikes, then recover (AIMD)",
+		},
+		cli.StringSliceFlag{
+			Name:  "host-rps",
+			Usage: "per-host rate override as host=rps (repeatable)",
+		},
+		cli.BoolFlag{
+			Name:  "head",
+			Usage: "check assets and links that are not crawled with HEAD, falling back to GET",
+		},
+		cli.BoolFlag{
+			Name:  "skip-non-html",
+			Usage: "do not download page bodies whose Content-Type is not HTML",
+		},
+		cli.Int64Flag{
+			Name:  "max-body-bytes",
+			Usage: "read at most N bytes of a page body (0 = unlimited)",
+			Value: 10 << 20,
+		},
+		cli.Int64Flag{
+			Name:  "max-asset-bytes",
+			Usage: "read at most N bytes of an asset body (0 = unlimited)",
+			Value: 10 << 20,
+		},
+		cli.Int64Flag{
+			Name:  "max-decompressed-bytes",
+			Usage: "keep at most N bytes of a body after gzip decoding (0 = unlimited)",
+			Value: 50 << 20,
+		},
+		cli.IntFlag{
+			Name:  "max-redirect-hops",
+			Usage: "flag redirect chains with more than N hops as too long",
+			Value: 5,
+		},
+		cli.IntFlag{
+			Name:  "circuit-breaker",
+			Usage: "fail fast for a host after N consecutive transport failures (0 = off)",
+		},
+		cli.DurationFlag{
+			Name:  "circuit-cooldown",
+			Usage: "how long an open circuit waits before a probe request",
+			Value: 30 * time.Second,
+		},
+		cli.BoolFlag{
+			Name:  "summary",
+			Usage: "print only a human-readable summary instead of the JSON report",
+		},
 		cli.StringFlag{
 			Name:  "user-agent",
 			Usage: "custom user agent",
@@ -55,7 +130,92 @@ func Run(args []string, stdout, stderr io.Writer, client *http.Client, clock lim
 			Usage: "number of concurrent workers",
 			Value: 4,
 		},
+		cli.BoolFlag{
+			Name:  "respect-robots",
+			Usage: "honor robots.txt rules and Crawl-delay",
+		},
+		cli.BoolFlag{
+			Name:  "respect-nofollow",
+			Usage: "do not crawl rel=nofollow links or links on nofollow pages; flag noindex pages",
+		},
+		cli.BoolFlag{
+			Name:  "sitemap",
+			Usage: "seed the crawl from robots.txt Sitemap lines or /sitemap.xml",
+		},
+		cli.StringFlag{
+			Name:  "scope",
+			Usage: "crawled site: origin, domain (include subdomains) or list (root plus --allow-origin)",
+			Value: string(scope.ModeOrigin),
+		},
+		cli.StringSliceFlag{
+			Name:  "allow-origin",
+			Usage: "extra origin crawled with --scope=list, e.g. https://docs.example.com (repeatable)",
+		},
+		cli.BoolFlag{
+			Name:  "ignore-scheme",
+			Usage: "treat http and https URLs of the same host as the same site",
+		},
+		cli.BoolFlag{
+			Name:  "strip-tracking",
+			Usage: "remove utm_*, fbclid and gclid query parameters from URLs",
+		},
+		cli.StringSliceFlag{
+			Name:  "strip-param",
+			Usage: "remove this query parameter from URLs; a trailing * matches any suffix (repeatable)",
+		},
+		cli.BoolFlag{
+			Name:  "sort-query",
+			Usage: "sort query parameters by name",
+		},
+		cli.StringFlag{
+			Name:  "trailing-slash",
+			Usage: "keep (/a and /a/ differ), ignore (they are one page) or strip (fetch /a/ as /a)",
+			Value: string(urlutil.TrailingSlashKeep),
+		},
+		cli.BoolFlag{
+			Name:  "keep-url-case",
+			Usage: "do not lowercase URL schemes and hosts",
+		},
+		cli.BoolFlag{
+			Name:  "keep-default-ports",
+			Usage: "do not drop :80 from http and :443 from https URLs",
+		},
+		cli.StringSliceFlag{
+			Name:  "include",
+			Usage: "crawl only pages whose path and query match (glob, or regex with re:; repeatable)",
+		},
+		cli.StringSliceFlag{
+			Name:  "exclude",
+			Usage: "never crawl pages whose path and query match (glob, or regex with re:; repeatable)",
+		},
+		cli.StringSliceFlag{
+			Name:  "check-include",
+			Usage: "check only links whose path and query match (repeatable)",
+		},
+		cli.StringSliceFlag{
+			Name:  "check-exclude",
+			Usage: "never check links whose path and query match (repeatable)",
+		},
+		cli.StringFlag{
+			Name:  "link-check",
+			Usage: "links to check: all, internal (links on the site only) or none",
+			Value: string(scope.LinkCheckAll),
+		},
+		cli.IntFlag{
+			Name:  "external-workers",
+			Usage: "number of concurrent checks of links to other sites (0 = same as for links on the site)",
+		},
+		cli.DurationFlag{
+			Name:  "external-timeout",
+			Usage: "per-request timeout for links to other sites (0 = --timeout)",
+		},
+		cli.BoolFlag{
+			Name:  "check-anchors",
+			Usage: "report links to #fragments the target page does not define",
+		},
 	}
+	app.Flags = append(app.Flags, retryFlags()...)
+	app.Flags = append(app.Flags, trapFlags()...)
 	app.Action = func(c *cli.Context) error {
 		rootURL := c.Args().First()
 		if rootURL == "" {
@@ -64,8 +224,11 @@ func Run(args []string, stdout, stderr io.Writer, client *http.Client, clock lim
 			return nil
 		}
 
-		client.Timeout = c.Duration("timeout")
-		options := optionsFromCLI(c, rootURL, client, clock)
+		client.Timeout = max(c.Duration("timeout"), c.Duration("external-timeout"))
+		options, err := optionsFromCLI(c, rootURL, client, clock)
+		if err != nil {
+			return err
+		}
 
 		report, _ := crawler.Analyze(context.Background(), options)
 
@@ -90,18 +253,138 @@ func optionsFromCLI(
 	rootURL string,
 	client *http.Client,
 	clock limiter.Timer,
-) crawler.Options {
+) (crawler.Options, error) {
+	hostRPS, err := parseHostRPS(c.StringSlice("host-rps"))
+	if err != nil {
+		return crawler.Options{}, err
+	}
+
+	pageRetry, assetRetry, err := retryPoliciesFromCLI(c)
+	if err != nil {
+		return crawler.Options{}, err
+	}
+
+	if err := validateScope(c, rootURL); err != nil {
+		return crawler.Options{}, err
+	}
+
+	if err := validatePatterns(c); err != nil {
+		return crawler.Options{}, err
+	}
+
+	if _, err := scope.ParseLinkCheck(c.String("link-check")); err != nil {
+		return crawler.Options{}, fmt.Errorf("invalid link check mode: %w", err)
+	}
+
+	if _, err := urlutil.ParseTrailingSlash(c.String("trailing-slash")); err != nil {
+		return crawler.Options{}, fmt.Errorf("invalid url normalization: %w", err)
+	}
+
 	return crawler.Options{
-		URL:         rootURL,
-		Depth:       c.Int("depth"),
-		IndentJSON:  true,
-		Timeout:     c.Duration("timeout"),
-		Delay:       c.Duration("delay"),
-		RPS:         c.Float64("rps"),
-		Retries:     c.Int("retries"),
-		UserAgent:   c.String("user-agent"),
-		Concurrency: c.Int("workers"),
-		HTTPClient:  client,
-		Clock:       clock,
+		URL:                     rootURL,
+		Depth:                   c.Int("depth"),
+		MaxPages:                c.Int("max-pages"),
+		MaxDuration:             c.Duration("max-duration"),
+		MaxURLsPerPathPrefix:    c.Int("max-urls-per-prefix"),
+		Traps:                   trapRulesFromCLI(c),
+		IndentJSON:              true,
+		SummaryOnly:             c.Bool("summary"),
+		Timeout:                 c.Duration("timeout"),
+		Delay:                   c.Duration("delay"),
+		RPS:                     c.Float64("rps"),
+		PerHostRPS:              c.Float64("per-host-rps"),
+		HostRPS:                 hostRPS,
+		Burst:                   c.Int("burst"),
+		AdaptiveRate:            c.Bool("adaptive"),
+		Retries:                 c.Int("retries"),
+		Retry:                   pageRetry,
+		AssetRetry:              assetRetry,
+		UserAgent:               c.String("user-agent"),
+		Concurrency:             c.Int("workers"),
+		RespectRobots:           c.Bool("respect-robots"),
+		RespectNofollow:         c.Bool("respect-nofollow"),
+		UseSitemap:              c.Bool("sitemap"),
+		Scope:                   c.String("scope"),
+		AllowedOrigins:          c.StringSlice("allow-origin"),
+		IgnoreScheme:            c.Bool("ignore-scheme"),
+		StripTrackingParams:     c.Bool("strip-tracking"),
+		StripParams:             c.StringSlice("strip-param"),
+		SortQuery:               c.Bool("sort-query"),
+		TrailingSlash:           c.String("trailing-slash"),
+		KeepURLCase:             c.Bool("keep-url-case"),
+		KeepDefaultPorts:        c.Bool("keep-default-ports"),
+		Include:                 c.StringSlice("include"),
+		Exclude:                 c.StringSlice("exclude"),
+		CheckInclude:            c.StringSlice("check-include"),
+		CheckExclude:            c.StringSlice("check-exclude"),
+		LinkCheck:               c.String("link-check"),
+		ExternalConcurrency:     c.Int("external-workers"),
+		ExternalTimeout:         c.Duration("external-timeout"),
+		CheckAnchors:            c.Bool("check-anchors"),
+		HeadChecks:              c.Bool("head"),
+		SkipNonHTMLBodies:       c.Bool("skip-non-html"),
+		MaxBodyBytes:            c.Int64("max-body-bytes"),
+		MaxAssetBytes:           c.Int64("max-asset-bytes"),
+		MaxDecompressedBytes:    c.Int64("max-decompressed-bytes"),
+		MaxRedirectHops:         c.Int("max-redirect-hops"),
+		CircuitBreakerThreshold: c.Int("circuit-breaker"),
+		CircuitBreakerCooldown:  c.Duration("circuit-cooldown"),
+		HTTPClient:              client,
+		Clock:                   clock,
+	}, nil
+}
+
+func validateScope(c *cli.Context, rootURL string) error {
+	mode, err := scope.ParseMode(c.String("scope"))
+	if err != nil {
+		return fmt.Errorf("invalid scope: %w", err)
+	}
+
+	// An unparsable root URL is reported by the crawler itself.
+	root, err := url.Parse(rootURL)
+	if err != nil {
+		return nil
+	}
+
+	if _, err := scope.NewSites(mode, root, c.StringSlice("allow-origin"), c.Bool("ignore-scheme")); err != nil {
+		return fmt.Errorf("invalid scope: %w", err)
+	}
+
+	return nil
+}
+
+func validatePatterns(c *cli.Context) error {
+	if _, err := scope.NewPatterns(c.StringSlice("include"), c.StringSlice("exclude")); err != nil {
+		return fmt.Errorf("invalid crawl pattern: %w", err)
 	}
+
+	if _, err := scope.NewPatterns(c.StringSlice("check-include"), c.StringSlice("check-exclude")); err != nil {
+		return fmt.Errorf("invalid link check pattern: %w", err)
+	}
+
+	return nil
+}
+
+func parseHostRPS(values []string) (map[string]float64, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	hostRPS := make(map[string]float64, len(values))
+	for _, value := range values {
+		host, rawRPS, found := strings.Cut(value, "=")
+		host = strings.TrimSpace(host)
+		if !found || host == "" {
+			return nil, fmt.Errorf("invalid --host-rps %q: expected host=rps", value)
+		}
+
+		rps, err := strconv.ParseFloat(strings.TrimSpace(rawRPS), 64)
+		if err != nil || rps < 0 {
+			return nil, fmt.Errorf("invalid --host-rps %q: rps must be a non-negative number", value)
+		}
+
+		hostRPS[host] = rps
+	}
+
+	return hostRPS, nil
 }
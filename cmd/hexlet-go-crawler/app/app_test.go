@@ -18,6 +18,7 @@ import (
 	"code/internal/limiter"
 
 	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
 )
 
 const cliFixtureBaseURL = "https://example.com"
@@ -230,3 +231,181 @@ func responseWithBody(status int, body []byte, header http.Header) *http.Respons
 		Body:       io.NopCloser(bytes.NewReader(body)),
 	}
 }
+
+func TestParseHostRPS(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseHostRPS([]string{"example.com=5", " docs.example.com = 0.5 "})
+	require.NoError(t, err)
+	require.Equal(t, map[string]float64{"example.com": 5, "docs.example.com": 0.5}, got)
+
+	got, err = parseHostRPS(nil)
+	require.NoError(t, err)
+	require.Nil(t, got)
+
+	for _, invalid := range []string{"example.com", "=5", "example.com=fast", "example.com=-1"} {
+		_, err = parseHostRPS([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestCLI_InvalidHostRPSReturnsError(t *testing.T) {
+	t.Parallel()
+
+	args := []string{
+		"hexlet-go-crawler",
+		"--host-rps=example.com",
+		cliFixtureBaseURL,
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	err := Run(args, &stdout, &stderr, newFixtureClient(t), fixedClock{now: fixtureTime()})
+	require.Error(t, err)
+	require.Empty(t, stdout.String())
+}
+
+func TestRetryPoliciesFromCLI(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantPage  crawler.RetryPolicy
+		wantAsset *crawler.RetryPolicy
+	}{
+		{
+			name:     "defaults",
+			args:     nil,
+			wantPage: crawler.RetryPolicy{Retries: 1, MaxRetryAfter: 30 * time.Second},
+		},
+		{
+			name: "asset flags override the page policy",
+			args: []string{
+				"--retries=3", "--retry-status=502", "--retry-status=503", "--retry-budget=10",
+				"--asset-retries=0", "--asset-retry-errors=timeout",
+			},
+			wantPage: crawler.RetryPolicy{
+				Retries:       3,
+				StatusCodes:   []int{502, 503},
+				MaxRetryAfter: 30 * time.Second,
+				Budget:        10,
+			},
+			wantAsset: &crawler.RetryPolicy{
+				Retries:       0,
+				StatusCodes:   []int{502, 503},
+				ErrorClasses:  []string{"timeout"},
+				MaxRetryAfter: 30 * time.Second,
+				Budget:        10,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var page, asset *crawler.RetryPolicy
+			app := cli.NewApp()
+			app.Flags = append([]cli.Flag{cli.IntFlag{Name: "retries", Value: 1}}, retryFlags()...)
+			app.Action = func(c *cli.Context) error {
+				var err error
+				page, asset, err = retryPoliciesFromCLI(c)
+
+				return err
+			}
+
+			require.NoError(t, app.Run(append([]string{"hexlet-go-crawler"}, tt.args...)))
+			require.Equal(t, tt.wantPage, *page)
+			require.Equal(t, tt.wantAsset, asset)
+		})
+	}
+}
+
+func TestCLI_InvalidRetryErrorClassReturnsError(t *testing.T) {
+	t.Parallel()
+
+	args := []string{
+		"hexlet-go-crawler",
+		"--retry-errors=eof",
+		cliFixtureBaseURL,
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	err := Run(args, &stdout, &stderr, newFixtureClient(t), fixedClock{now: fixtureTime()})
+	require.Error(t, err)
+	require.Empty(t, stdout.String())
+}
+
+func TestCLI_InvalidPatternReturnsError(t *testing.T) {
+	t.Parallel()
+
+	args := []string{
+		"hexlet-go-crawler",
+		"--exclude=re:(",
+		cliFixtureBaseURL,
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	err := Run(args, &stdout, &stderr, newFixtureClient(t), fixedClock{now: fixtureTime()})
+	require.ErrorContains(t, err, "invalid crawl pattern")
+	require.Empty(t, stdout.String())
+}
+
+func TestCLI_AllowedOriginsRequireListScope(t *testing.T) {
+	t.Parallel()
+
+	args := []string{
+		"hexlet-go-crawler",
+		"--allow-origin=https://docs.example.com",
+		cliFixtureBaseURL,
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	err := Run(args, &stdout, &stderr, newFixtureClient(t), fixedClock{now: fixtureTime()})
+	require.ErrorContains(t, err, "invalid scope")
+	require.Empty(t, stdout.String())
+}
+
+func TestCLI_InvalidLinkCheckModeFails(t *testing.T) {
+	t.Parallel()
+
+	args := []string{
+		"hexlet-go-crawler",
+		"--link-check=external",
+		cliFixtureBaseURL,
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	err := Run(args, &stdout, &stderr, newFixtureClient(t), fixedClock{now: fixtureTime()})
+	require.ErrorContains(t, err, "invalid link check mode")
+	require.Empty(t, stdout.String())
+}
+
+func TestCLI_SummaryPrintsTextOnly(t *testing.T) {
+	t.Parallel()
+
+	args := []string{
+		"hexlet-go-crawler",
+		"--depth=1",
+		"--workers=1",
+		"--retries=0",
+		"--summary",
+		cliFixtureBaseURL,
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	err := Run(args, &stdout, &stderr, newFixtureClient(t), fixedClock{now: fixtureTime()})
+	require.NoError(t, err)
+
+	output := stdout.String()
+	require.False(t, json.Valid(stdout.Bytes()))
+	require.Contains(t, output, "Site:             https://example.com\n")
+	require.Contains(t, output, "Pages:            1\n")
+	require.True(t, strings.HasSuffix(output, "\n"))
+}
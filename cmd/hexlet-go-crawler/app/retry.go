@@ -0,0 +1,162 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli"
+
+	"code/crawler"
+	"code/internal/fetcher"
+)
+
+const (
+	pageRetryPrefix  = "retry-"
+	assetRetryPrefix = "asset-retry-"
+	assetRetriesFlag = "asset-retries"
+)
+
+// retrySetter copies one retry flag into a policy.
+type retrySetter struct {
+	suffix string
+	apply  func(c *cli.Context, name string, policy *crawler.RetryPolicy)
+}
+
+var retrySetters = []retrySetter{
+	{suffix: "status", apply: func(c *cli.Context, name string, policy *crawler.RetryPolicy) {
+		if codes := c.IntSlice(name); len(codes) > 0 {
+			policy.StatusCodes = codes
+		}
+	}},
+	{suffix: "errors", apply: func(c *cli.Context, name string, policy *crawler.RetryPolicy) {
+		if classes := c.StringSlice(name); len(classes) > 0 {
+			policy.ErrorClasses = classes
+		}
+	}},
+	{suffix: "base-delay", apply: func(c *cli.Context, name string, policy *crawler.RetryPolicy) {
+		policy.BaseDelay = c.Duration(name)
+	}},
+	{suffix: "max-delay", apply: func(c *cli.Context, name string, policy *crawler.RetryPolicy) {
+		policy.MaxDelay = c.Duration(name)
+	}},
+	{suffix: "multiplier", apply: func(c *cli.Context, name string, policy *crawler.RetryPolicy) {
+		policy.Multiplier = c.Float64(name)
+	}},
+	{suffix: "jitter", apply: func(c *cli.Context, name string, policy *crawler.RetryPolicy) {
+		policy.Jitter = c.Float64(name)
+	}},
+	{suffix: "after-max", apply: func(c *cli.Context, name string, policy *crawler.RetryPolicy) {
+		policy.MaxRetryAfter = c.Duration(name)
+	}},
+	{suffix: "budget", apply: func(c *cli.Context, name string, policy *crawler.RetryPolicy) {
+		policy.Budget = c.Int(name)
+	}},
+}
+
+// retryFlags returns the page retry policy flags and their asset-check counterparts.
+func retryFlags() []cli.Flag {
+	flags := policyFlags(pageRetryPrefix, "")
+	flags = append(flags, cli.IntFlag{
+		Name:  assetRetriesFlag,
+		Usage: "retries for asset checks (asset flags default to the page policy)",
+	})
+
+	return append(flags, policyFlags(assetRetryPrefix, "asset checks: ")...)
+}
+
+func policyFlags(prefix, usagePrefix string) []cli.Flag {
+	return []cli.Flag{
+		cli.IntSliceFlag{
+			Name:  prefix + "status",
+			Usage: usagePrefix + "retryable HTTP status (repeatable, default 429 and 5xx)",
+		},
+		cli.StringSliceFlag{
+			Name:  prefix + "errors",
+			Usage: usagePrefix + "retryable error class: dns, connection_refused, timeout, tls (repeatable)",
+		},
+		cli.DurationFlag{
+			Name:  prefix + "base-delay",
+			Usage: usagePrefix + "first retry delay (default --delay or 100ms)",
+		},
+		cli.DurationFlag{
+			Name:  prefix + "max-delay",
+			Usage: usagePrefix + "upper bound for the retry delay (default 2s)",
+		},
+		cli.Float64Flag{
+			Name:  prefix + "multiplier",
+			Usage: usagePrefix + "retry delay growth factor (default 2)",
+		},
+		cli.Float64Flag{
+			Name:  prefix + "jitter",
+			Usage: usagePrefix + "randomly shorten retry backoff by up to this fraction (0..1)",
+		},
+		cli.DurationFlag{
+			Name:  prefix + "after-max",
+			Usage: usagePrefix + "upper bound for waits requested by Retry-After headers",
+			Value: 30 * time.Second,
+		},
+		cli.IntFlag{
+			Name:  prefix + "budget",
+			Usage: usagePrefix + "maximum retries for the whole crawl (0 = unlimited)",
+		},
+	}
+}
+
+// retryPoliciesFromCLI builds the page policy and, if any asset flag is set, the asset policy.
+func retryPoliciesFromCLI(c *cli.Context) (*crawler.RetryPolicy, *crawler.RetryPolicy, error) {
+	page := crawler.RetryPolicy{Retries: c.Int("retries")}
+	applyRetryFlags(c, pageRetryPrefix, &page, false)
+
+	if err := validateErrorClasses(page.ErrorClasses); err != nil {
+		return nil, nil, err
+	}
+
+	if !assetRetrySet(c) {
+		return &page, nil, nil
+	}
+
+	asset := page
+	if c.IsSet(assetRetriesFlag) {
+		asset.Retries = c.Int(assetRetriesFlag)
+	}
+	applyRetryFlags(c, assetRetryPrefix, &asset, true)
+
+	if err := validateErrorClasses(asset.ErrorClasses); err != nil {
+		return nil, nil, err
+	}
+
+	return &page, &asset, nil
+}
+
+func applyRetryFlags(c *cli.Context, prefix string, policy *crawler.RetryPolicy, onlySet bool) {
+	for _, setter := range retrySetters {
+		name := prefix + setter.suffix
+		if !onlySet || c.IsSet(name) {
+			setter.apply(c, name, policy)
+		}
+	}
+}
+
+func assetRetrySet(c *cli.Context) bool {
+	if c.IsSet(assetRetriesFlag) {
+		return true
+	}
+
+	for _, setter := range retrySetters {
+		if c.IsSet(assetRetryPrefix + setter.suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateErrorClasses(classes []string) error {
+	for _, class := range classes {
+		if _, err := fetcher.ParseErrorClass(class); err != nil {
+			return fmt.Errorf("invalid retry error class: %w", err)
+		}
+	}
+
+	return nil
+}
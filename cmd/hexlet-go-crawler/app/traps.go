@@ -0,0 +1,51 @@
+package app
+
+import (
+	"github.com/urfave/cli"
+
+	"code/crawler"
+)
+
+const detectTrapsFlag = "detect-traps"
+
+// trapFlags returns the crawler-trap detection flags.
+func trapFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.BoolFlag{
+			Name:  detectTrapsFlag,
+			Usage: "skip links that look like crawler traps and list them in the report",
+		},
+		cli.IntFlag{
+			Name:  "trap-max-repeats",
+			Usage: "how often one path segment may appear in a URL (default 2; implies --detect-traps)",
+		},
+		cli.IntFlag{
+			Name:  "trap-max-url-length",
+			Usage: "longest URL crawled (default 2000; implies --detect-traps)",
+		},
+		cli.IntFlag{
+			Name:  "trap-max-query-params",
+			Usage: "most query parameters in a URL crawled (default 10; implies --detect-traps)",
+		},
+		cli.IntFlag{
+			Name:  "trap-max-numeric-variants",
+			Usage: "how many URLs may differ only in numbers (default 100; implies --detect-traps)",
+		},
+	}
+}
+
+// trapRulesFromCLI returns nil unless --detect-traps or any trap limit is set.
+func trapRulesFromCLI(c *cli.Context) *crawler.TrapRules {
+	rules := crawler.TrapRules{
+		MaxRepeatedSegments: c.Int("trap-max-repeats"),
+		MaxURLLength:        c.Int("trap-max-url-length"),
+		MaxQueryParams:      c.Int("trap-max-query-params"),
+		MaxNumericVariants:  c.Int("trap-max-numeric-variants"),
+	}
+
+	if !c.Bool(detectTrapsFlag) && rules == (crawler.TrapRules{}) {
+		return nil
+	}
+
+	return &rules
+}
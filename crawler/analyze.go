@@ -6,10 +6,13 @@ import (
 	"sort"
 )
 
-// Analyze crawls a site and returns a JSON report as bytes.
-// IndentJSON affects formatting only, and the output always ends with a newline.
+// Analyze crawls a site and returns a JSON report as bytes, or the summary as text with
+// SummaryOnly. IndentJSON affects formatting only, and the output always ends with a newline.
 func Analyze(ctx context.Context, opts Options) ([]byte, error) {
 	report, err := analyzeReport(ctx, opts)
+	if opts.SummaryOnly {
+		return formatSummary(report.RootURL, report.Summary), err
+	}
 
 	return marshalReport(report, opts.IndentJSON), err
 }
@@ -8,10 +8,14 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"code/internal/breaker"
 	"code/internal/fetcher"
 	"code/internal/limiter"
+	"code/internal/sitemap"
+	"code/internal/urlutil"
 )
 
 const (
@@ -20,16 +24,34 @@ const (
 	statusError      = "error"
 )
 
-// analyzeReport crawls a site and returns a report.
+// analyzeReport crawls a site and returns a report with its summary.
 func analyzeReport(ctx context.Context, opts Options) (Report, error) {
 	opts = normalizeAnalyzeOptions(opts)
+	start := opts.Clock.Now()
+
+	var transferred atomic.Int64
+	opts.HTTPClient = withTransferCount(opts.HTTPClient, &transferred)
+
+	report, err := crawlReport(ctx, opts)
+	report.Summary = summarize(report.Pages, transferred.Load(), opts.Clock.Now().Sub(start))
+
+	return report, err
+}
+
+// crawlReport crawls a site and returns a report without the summary.
+func crawlReport(ctx context.Context, opts Options) (Report, error) {
 	report := newReport(opts)
 
 	if opts.URL == "" {
 		return report, errors.New("url is required")
 	}
 
-	baseURL, err := parseRootURL(opts.URL)
+	normalizer, err := newNormalizer(opts)
+	if err != nil {
+		return report, err
+	}
+
+	baseURL, err := parseRootURL(opts.URL, normalizer)
 	if err != nil {
 		page := newPage(opts.URL, 0, opts.Clock.Now())
 		page.Status = statusError
@@ -39,26 +61,53 @@ func analyzeReport(ctx context.Context, opts Options) (Report, error) {
 		return report, fmt.Errorf("invalid root url: %w", err)
 	}
 
-	baseURL.Fragment = ""
-	rootURL := baseURL.String()
-	report.RootURL = rootURL
+	report.RootURL = baseURL.String()
 
-	rateInterval := rateInterval(opts)
-	rateLimiter := limiter.NewWithTimer(rateInterval, opts.Clock)
+	site, err := newSiteScope(opts, baseURL)
+	if err != nil {
+		return report, err
+	}
+
+	if err := validateRetryPolicies(opts); err != nil {
+		return report, err
+	}
+
+	limits := newLimiterRegistry(opts)
+	breakers := breaker.NewRegistry(breaker.Config{
+		Threshold: opts.CircuitBreakerThreshold,
+		Cooldown:  opts.CircuitBreakerCooldown,
+	}, opts.Clock)
 
 	fetch := fetcher.New(
 		opts.HTTPClient,
 		opts.Timeout,
 		opts.UserAgent,
-		rateLimiter,
-		opts.Retries,
-		opts.Delay,
+		limits,
+		breakers,
+		pageRetryPolicy(opts),
 		opts.Clock,
 	)
-
-	analyzer := newAnalyzer(opts, baseURL, fetch, &report)
+	robotsRules := newRobotsRegistry(opts, fetch, limits)
+
+	pageLimits := bodyLimits(opts.MaxBodyBytes, opts)
+	pageLimits.HTMLOnly = opts.SkipNonHTMLBodies
+	pageFetch := fetch.WithLimits(pageLimits)
+	assetFetch := newAssetFetcher(opts, fetch, limits, breakers).WithLimits(bodyLimits(opts.MaxAssetBytes, opts))
+
+	analyzer := newAnalyzer(opts, baseURL, pageFetch, robotsRules, &report)
+	analyzer.assetFetch = assetFetch
+	analyzer.externalFetch = newExternalFetcher(opts, pageFetch)
+	analyzer.sitemapFetch = fetch.WithLimits(bodyLimits(sitemap.MaxUncompressedBytes, opts))
+	analyzer.normalizer = normalizer
+	analyzer.useScope(site)
 	analysisErr := analyzer.run(ctx)
 
+	if opts.AdaptiveRate {
+		report.RateControl = hostRates(limits.AdaptiveStats())
+	}
+
+	report.CircuitBreakers = hostCircuits(breakers.Stats())
+
 	return report, analysisErr
 }
 
@@ -101,14 +150,65 @@ func newPage(pageURL string, depth int, discoveredAt time.Time) Page {
 	}
 }
 
+// newLimiterRegistry builds per-host limiters with RPS/Delay as the global ceiling.
+func newLimiterRegistry(opts Options) *limiter.Registry {
+	global := limiter.NewRate(rateInterval(opts), opts.Burst, opts.Clock)
+
+	overrides := make(map[string]time.Duration, len(opts.HostRPS))
+	for host, rps := range opts.HostRPS {
+		overrides[host] = rpsInterval(rps)
+	}
+
+	registry := limiter.NewRegistry(global, rpsInterval(opts.PerHostRPS), opts.Burst, overrides, opts.Clock)
+	if opts.AdaptiveRate {
+		registry.EnableAdaptive(limiter.AdaptiveConfig{})
+	}
+
+	return registry
+}
+
+func hostRates(stats []limiter.HostStats) []HostRate {
+	rates := make([]HostRate, 0, len(stats))
+	for _, stat := range stats {
+		rates = append(rates, HostRate{
+			Host:            stat.Host,
+			FinalIntervalMs: stat.Interval.Milliseconds(),
+			MaxIntervalMs:   stat.MaxInterval.Milliseconds(),
+			Backoffs:        stat.Backoffs,
+		})
+	}
+
+	return rates
+}
+
+func bodyLimits(maxBytes int64, opts Options) fetcher.BodyLimits {
+	return fetcher.BodyLimits{
+		MaxBodyBytes:         maxBytes,
+		MaxDecompressedBytes: opts.MaxDecompressedBytes,
+	}
+}
+
+func hostCircuits(stats []breaker.HostStats) []HostCircuit {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	circuits := make([]HostCircuit, 0, len(stats))
+	for _, stat := range stats {
+		circuits = append(circuits, HostCircuit{
+			Host:     stat.Host,
+			State:    string(stat.State),
+			Trips:    stat.Trips,
+			Rejected: stat.Rejected,
+		})
+	}
+
+	return circuits
+}
+
 func rateInterval(opts Options) time.Duration {
 	if opts.RPS > 0 {
-		interval := time.Duration(float64(time.Second) / opts.RPS)
-		if interval <= 0 {
-			return time.Nanosecond
-		}
-
-		return interval
+		return rpsInterval(opts.RPS)
 	}
 
 	delay := opts.Delay
@@ -123,7 +223,20 @@ func rateInterval(opts Options) time.Duration {
 	return 0
 }
 
-func parseRootURL(rawURL string) (*url.URL, error) {
+func rpsInterval(rps float64) time.Duration {
+	if rps <= 0 {
+		return 0
+	}
+
+	interval := time.Duration(float64(time.Second) / rps)
+	if interval <= 0 {
+		return time.Nanosecond
+	}
+
+	return interval
+}
+
+func parseRootURL(rawURL string, normalizer urlutil.Normalizer) (*url.URL, error) {
 	parsed, err := url.Parse(rawURL)
 	if err != nil {
 		return nil, err
@@ -133,22 +246,25 @@ func parseRootURL(rawURL string) (*url.URL, error) {
 		return nil, errors.New("missing scheme or host")
 	}
 
-	if parsed.Path == "/" {
-		parsed.Path = ""
-		parsed.RawPath = ""
-	}
-
-	parsed.Fragment = ""
+	normalizer.NormalizeURL(parsed)
 
 	return parsed, nil
 }
 
-func fetchAssetResult(ctx context.Context, fetch *fetcher.Fetcher, absoluteURL string) assetFetchResult {
-	result, err := fetch.Fetch(ctx, absoluteURL)
+func fetchAssetResult(
+	ctx context.Context,
+	fetch *fetcher.Fetcher,
+	absoluteURL string,
+	head bool,
+	redirects redirectPolicy,
+) assetFetchResult {
+	result, err := fetchAsset(ctx, fetch, absoluteURL, head)
 	fetchResult := assetFetchResult{
 		statusCode: result.StatusCode,
 		sizeBytes:  0,
+		truncated:  result.Truncated,
 		err:        "",
+		redirect:   redirects.describe(absoluteURL, result, err),
 	}
 
 	errMsg := ""
@@ -204,6 +320,37 @@ func sizeFromResult(result fetcher.Result) (int64, error) {
 	return int64(len(result.Body)), nil
 }
 
+// nonHTMLPageResult lists a page that is not HTML without parsing it.
+func nonHTMLPageResult(job crawlJob, page Page, result fetcher.Result) pageResult {
+	page.Status = statusOK
+	page.SizeBytes = int64(len(result.Body))
+
+	if contentLength := result.Header.Get("Content-Length"); contentLength != "" {
+		if size, err := parseContentLength(contentLength); err == nil {
+			page.SizeBytes = size
+		}
+	}
+
+	return pageResult{
+		job:  job,
+		page: page,
+	}
+}
+
+// parseErrorResult marks a page whose body could not be decoded or parsed.
+func parseErrorResult(job crawlJob, page Page, err error) pageResult {
+	page.Status = statusError
+	page.Error = fmt.Sprintf("parse html: %v", err)
+	page.BrokenLinks = nil
+	page.Assets = nil
+
+	return pageResult{
+		job:  job,
+		page: page,
+		err:  fmt.Errorf("parse html: %w", err),
+	}
+}
+
 func parseContentLength(value string) (int64, error) {
 	trimmed := strings.TrimSpace(value)
 
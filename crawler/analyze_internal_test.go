@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"code/internal/fetcher"
+	"code/internal/urlutil"
 )
 
 func TestRateInterval(t *testing.T) {
@@ -54,7 +55,7 @@ func TestParseRootURL(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got, err := parseRootURL(tt.raw)
+			got, err := parseRootURL(tt.raw, urlutil.Normalizer{})
 			if tt.wantErr {
 				if err == nil {
 					t.Fatalf("expected error")
@@ -185,6 +186,12 @@ func TestNormalizeHelpers(t *testing.T) {
 	if got := linkCheckPoolSize(Options{Concurrency: 10}); got != 2 {
 		t.Fatalf("linkCheckPoolSize() = %d", got)
 	}
+	if got := externalCheckPoolSize(Options{Concurrency: 10}); got != 2 {
+		t.Fatalf("externalCheckPoolSize() = %d", got)
+	}
+	if got := externalCheckPoolSize(Options{Concurrency: 1, ExternalConcurrency: 8}); got != 8 {
+		t.Fatalf("externalCheckPoolSize() = %d", got)
+	}
 }
 
 func TestResolveLinksSkipsInvalid(t *testing.T) {
@@ -236,7 +243,7 @@ func TestBuildLinkResults_DeduplicatesBrokenLinksByCanonicalURL(t *testing.T) {
 
 	processed := []bool{true, true}
 
-	brokenLinks, crawlLinks := buildLinkResults(results, processed)
+	brokenLinks, crawlLinks := buildLinkResults(results, processed, urlutil.Normalizer{})
 	if len(crawlLinks) != 0 {
 		t.Fatalf("len(crawlLinks) = %d; want 0", len(crawlLinks))
 	}
@@ -258,7 +265,7 @@ func TestDedupBrokenLinks_CanonicalizesAndDeduplicates(t *testing.T) {
 		{URL: "http://example.com/missing#frag", StatusCode: 404, Error: "Not Found"},
 	}
 
-	deduped := dedupBrokenLinks(links)
+	deduped := dedupBrokenLinks(links, urlutil.Normalizer{})
 	if len(deduped) != 1 {
 		t.Fatalf("len(deduped) = %d; want 1", len(deduped))
 	}
@@ -268,7 +275,7 @@ func TestDedupBrokenLinks_CanonicalizesAndDeduplicates(t *testing.T) {
 	}
 }
 
-func TestCanonicalBrokenURL(t *testing.T) {
+func TestBrokenLinkNormalizer(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
@@ -291,6 +298,11 @@ func TestCanonicalBrokenURL(t *testing.T) {
 			raw:  "https://Example.com:8443/missing",
 			want: "https://example.com:8443/missing",
 		},
+		{
+			name: "trailing slash removed",
+			raw:  "https://example.com/missing/",
+			want: "https://example.com/missing",
+		},
 		{
 			name: "invalid raw passthrough",
 			raw:  "://bad",
@@ -298,14 +310,15 @@ func TestCanonicalBrokenURL(t *testing.T) {
 		},
 	}
 
+	normalizer := brokenLinkNormalizer(urlutil.Normalizer{})
+
 	for _, tc := range tests {
-		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			got := canonicalBrokenURL(tc.raw)
+			got := normalizer.Normalize(tc.raw)
 			if got != tc.want {
-				t.Fatalf("canonicalBrokenURL(%q) = %q; want %q", tc.raw, got, tc.want)
+				t.Fatalf("Normalize(%q) = %q; want %q", tc.raw, got, tc.want)
 			}
 		})
 	}
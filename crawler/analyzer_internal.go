@@ -2,17 +2,14 @@ package crawler
 
 import (
 	"context"
-	"fmt"
-	"net"
 	"net/http"
 	"net/url"
-	"strconv"
-	"strings"
 	"sync"
 	"time"
 
 	"code/internal/fetcher"
 	"code/internal/limiter"
+	"code/internal/mediatype"
 	"code/internal/parser"
 	"code/internal/urlutil"
 
@@ -22,15 +19,21 @@ import (
 type crawlJob struct {
 	url          string
 	depth        int
+	source       string
 	discoveredAt time.Time
 	seq          uint64
 }
 
+// pageResult is the outcome of one crawl job. mergeInto is set, to the key of the page that
+// crawls the document, when another job already claimed the document this job ended at.
 type pageResult struct {
-	job   crawlJob
-	page  Page
-	links []string
-	err   error
+	job       crawlJob
+	page      Page
+	links     []string
+	referrers map[string][]LinkReferrer
+	err       error
+	skipped   bool
+	mergeInto string
 }
 
 type linkCheck struct {
@@ -42,6 +45,7 @@ type linkCheck struct {
 type linkCheckJob struct {
 	idx      int
 	url      string
+	crawled  bool
 	resultCh chan<- linkCheckResult
 }
 
@@ -64,53 +68,81 @@ type assetCacheEntry struct {
 type assetFetchResult struct {
 	statusCode int
 	sizeBytes  int64
+	truncated  bool
 	err        string
+	redirect   *Redirect
 }
 
 type analyzer struct {
-	options    Options
-	baseURL    *url.URL
-	fetch      *fetcher.Fetcher
-	report     *Report
-	maxDepth   int
-	fetchSem   *semaphore.Weighted
-	linkCheck  *linkChecker
-	fetchMu    sync.Mutex
-	fetchCache map[string]*fetchCacheEntry
-	assetMu    sync.Mutex
-	assetCache map[string]*assetCacheEntry
+	options        Options
+	baseURL        *url.URL
+	site           siteScope
+	normalizer     urlutil.Normalizer
+	fetch          *fetcher.Fetcher
+	assetFetch     *fetcher.Fetcher
+	externalFetch  *fetcher.Fetcher
+	sitemapFetch   *fetcher.Fetcher
+	robots         *robotsRegistry
+	redirects      redirectPolicy
+	claims         *pageClaims
+	budget         *crawlBudget
+	report         *Report
+	maxDepth       int
+	fetchSem       *semaphore.Weighted
+	linkCheck      *linkChecker
+	externalCheck  *linkChecker
+	fetchMu        sync.Mutex
+	fetchCache     map[string]*fetchCacheEntry
+	assetMu        sync.Mutex
+	assetCache     map[string]*assetCacheEntry
+	externalMu     sync.Mutex
+	externalChecks map[string]*externalCheckEntry
+	anchorMu       sync.Mutex
+	anchors        map[string]map[string]bool
+	sitemap        []string
 }
 
 type crawlState struct {
 	seen        map[string]bool
+	links       map[string][]string
 	analysisErr error
 }
 
 type aggregator struct {
 	clock        limiter.Timer
 	state        *crawlState
+	claims       *pageClaims
 	jobs         chan crawlJob
+	queue        []crawlJob
 	pending      int
 	jobsClosed   bool
 	maxDepth     int
 	report       *Report
-	baseURL      *url.URL
+	site         siteScope
+	normalizer   urlutil.Normalizer
+	budget       *crawlBudget
+	traps        *trapTracker
 	nextSeq      uint64
 	nextCommit   uint64
-	pendingPages map[uint64]Page
+	pendingPages map[uint64]*Page
+	finalPages   map[string]int
+	aliases      map[string][]Page
+	referrers    map[string]map[string][]LinkReferrer
 }
 
 type linkChecker struct {
-	analyzer *analyzer
-	jobs     chan linkCheckJob
-	wg       sync.WaitGroup
+	jobs chan linkCheckJob
+	wg   sync.WaitGroup
 }
 
-func newLinkChecker(ctx context.Context, analyzer *analyzer, workerCount int) *linkChecker {
+func newLinkChecker(
+	ctx context.Context,
+	workerCount int,
+	check func(ctx context.Context, absoluteURL string, crawled bool) (BrokenLink, bool),
+) *linkChecker {
 	jobs := make(chan linkCheckJob, workerCount*4)
 	checker := &linkChecker{
-		analyzer: analyzer,
-		jobs:     jobs,
+		jobs: jobs,
 	}
 
 	for range workerCount {
@@ -119,7 +151,7 @@ func newLinkChecker(ctx context.Context, analyzer *analyzer, workerCount int) *l
 			defer checker.wg.Done()
 
 			for job := range jobs {
-				brokenLink, broken := analyzer.checkBrokenLink(ctx, job.url)
+				brokenLink, broken := check(ctx, job.url, job.crawled)
 				job.resultCh <- linkCheckResult{
 					idx: job.idx,
 					check: linkCheck{
@@ -140,19 +172,41 @@ func (c *linkChecker) stop() {
 	c.wg.Wait()
 }
 
-func newAnalyzer(options Options, baseURL *url.URL, fetch *fetcher.Fetcher, report *Report) *analyzer {
+func newAnalyzer(
+	options Options,
+	baseURL *url.URL,
+	fetch *fetcher.Fetcher,
+	robots *robotsRegistry,
+	report *Report,
+) *analyzer {
 	maxConcurrentFetch := normalizeMaxConcurrentFetch(options)
 
-	return &analyzer{
-		options:    options,
-		baseURL:    baseURL,
-		fetch:      fetch,
-		report:     report,
-		maxDepth:   normalizeMaxDepth(options.Depth),
-		fetchSem:   semaphore.NewWeighted(int64(maxConcurrentFetch)),
-		fetchCache: map[string]*fetchCacheEntry{},
-		assetCache: map[string]*assetCacheEntry{},
+	a := &analyzer{
+		options:        options,
+		baseURL:        baseURL,
+		fetch:          fetch,
+		assetFetch:     fetch,
+		externalFetch:  fetch,
+		sitemapFetch:   fetch,
+		robots:         robots,
+		claims:         newPageClaims(),
+		report:         report,
+		maxDepth:       normalizeMaxDepth(options.Depth),
+		fetchSem:       semaphore.NewWeighted(int64(maxConcurrentFetch)),
+		fetchCache:     map[string]*fetchCacheEntry{},
+		assetCache:     map[string]*assetCacheEntry{},
+		externalChecks: map[string]*externalCheckEntry{},
+		anchors:        map[string]map[string]bool{},
 	}
+	a.useScope(exactOriginScope(baseURL))
+
+	return a
+}
+
+// useScope sets the site scope used for crawling, link checks and redirect reporting.
+func (a *analyzer) useScope(site siteScope) {
+	a.site = site
+	a.redirects = newRedirectPolicy(a.options, site)
 }
 
 func (a *analyzer) run(ctx context.Context) error {
@@ -161,11 +215,14 @@ func (a *analyzer) run(ctx context.Context) error {
 		workerCount = 1
 	}
 
-	linkCheckWorkers := linkCheckPoolSize(a.options)
+	a.budget = newCrawlBudget(a.options, a.options.Clock.Now())
 
-	a.linkCheck = newLinkChecker(ctx, a, linkCheckWorkers)
+	a.linkCheck = newLinkChecker(ctx, linkCheckPoolSize(a.options), a.checkBrokenLink)
 	defer a.linkCheck.stop()
 
+	a.externalCheck = newLinkChecker(ctx, externalCheckPoolSize(a.options), a.checkExternalLink)
+	defer a.externalCheck.stop()
+
 	jobBuffer := workerCount * 4
 	if jobBuffer < 16 {
 		jobBuffer = 16
@@ -190,27 +247,51 @@ func (a *analyzer) run(ctx context.Context) error {
 	}()
 
 	state := &crawlState{
-		seen: map[string]bool{},
+		seen:  map[string]bool{},
+		links: map[string][]string{},
 	}
 
 	agg := &aggregator{
 		clock:        a.options.Clock,
 		state:        state,
+		claims:       a.claims,
 		jobs:         jobs,
 		maxDepth:     a.maxDepth,
 		report:       a.report,
-		baseURL:      a.baseURL,
-		pendingPages: make(map[uint64]Page),
+		site:         a.site,
+		normalizer:   a.normalizer,
+		budget:       a.budget,
+		traps:        newTrapTracker(a.options),
+		pendingPages: make(map[uint64]*Page),
+		finalPages:   make(map[string]int),
+		aliases:      make(map[string][]Page),
+		referrers:    make(map[string]map[string][]LinkReferrer),
 	}
 
 	agg.enqueue(ctx, crawlJob{
 		url:          a.baseURL.String(),
 		depth:        0,
+		source:       sourceRoot,
 		discoveredAt: a.options.Clock.Now(),
 	})
+
+	if a.options.UseSitemap {
+		a.seedFromSitemaps(ctx, agg)
+	}
+
 	agg.closeJobsIfNeeded()
 
-	return a.drainResults(ctx, agg, results)
+	err := a.drainResults(ctx, agg, results)
+	agg.releaseAliases()
+	a.report.Budget = a.budget.summary()
+	a.report.Traps = agg.traps.report()
+	a.report.BrokenLinks = buildBrokenLinkIndex(a.report.Pages, agg.referrers, brokenLinkNormalizer(a.normalizer))
+
+	if a.options.UseSitemap {
+		a.report.Orphans = a.findOrphans(state)
+	}
+
+	return err
 }
 
 func (a *analyzer) acquireFetch(ctx context.Context) bool {
@@ -221,59 +302,99 @@ func (a *analyzer) releaseFetch() {
 	a.fetchSem.Release(1)
 }
 
+// drainResults dispatches queued jobs and consumes page results until workers finish.
+// Dispatching and draining share one select so a large batch of new links never blocks workers.
 func (a *analyzer) drainResults(
 	ctx context.Context,
 	agg *aggregator,
 	results <-chan pageResult,
 ) error {
-	canceled := false
+	done := ctx.Done()
 	for {
-		if !canceled {
-			select {
-			case result, ok := <-results:
-				if !ok {
-					return agg.state.analysisErr
-				}
-				agg.onResult(ctx, result)
-			case <-ctx.Done():
-				canceled = true
-				agg.closeJobsIfNeeded()
-			}
+		agg.enforceDeadline()
+		jobs, next := agg.nextDispatch()
 
-			continue
-		}
-
-		result, ok := <-results
-		if !ok {
-			return agg.state.analysisErr
+		select {
+		case jobs <- next:
+			agg.dispatched()
+		case result, ok := <-results:
+			if !ok {
+				return agg.state.analysisErr
+			}
+			agg.onResult(ctx, result)
+		case <-done:
+			done = nil
+			agg.dropQueued()
+			agg.closeJobsIfNeeded()
 		}
-
-		agg.onResult(ctx, result)
 	}
 }
 
 func (a *analyzer) worker(ctx context.Context, jobs <-chan crawlJob, results chan<- pageResult) {
 	for job := range jobs {
+		if job.source != sourceRoot && a.budget.expired() {
+			results <- pageResult{job: job, skipped: true}
+
+			continue
+		}
+
 		result := a.processJob(ctx, job)
 		results <- result
 	}
 }
 
 func (a *aggregator) enqueue(ctx context.Context, job crawlJob) {
-	if a.state.seen[job.url] {
+	key := a.normalizer.Key(job.url)
+	if a.state.seen[key] || a.claims.taken(key) || ctx.Err() != nil {
 		return
 	}
 
-	jobWithSeq := job
-	jobWithSeq.seq = a.nextSeq
+	a.state.seen[key] = true
+	if job.source != sourceRoot && a.traps.flag(job.url) {
+		return
+	}
+
+	if !a.budget.admit(job.url) {
+		return
+	}
+
+	a.queue = append(a.queue, job)
+	a.pending++
+}
+
+// nextDispatch returns the jobs channel and the head of the queue, or a nil channel when idle.
+func (a *aggregator) nextDispatch() (chan<- crawlJob, crawlJob) {
+	if len(a.queue) == 0 || a.jobsClosed {
+		return nil, crawlJob{}
+	}
+
+	next := a.queue[0]
+	next.seq = a.nextSeq
+
+	return a.jobs, next
+}
+
+func (a *aggregator) dispatched() {
+	a.queue[0] = crawlJob{}
+	a.queue = a.queue[1:]
+	a.nextSeq++
+}
+
+func (a *aggregator) dropQueued() {
+	a.pending -= len(a.queue)
+	a.queue = nil
+}
 
-	select {
-	case a.jobs <- jobWithSeq:
-		a.state.seen[job.url] = true
-		a.nextSeq++
-		a.pending++
-	case <-ctx.Done():
+// enforceDeadline drops the queue once MaxDuration has passed; pages already being
+// fetched still finish and are reported.
+func (a *aggregator) enforceDeadline() {
+	if len(a.queue) == 0 || !a.budget.expired() {
+		return
 	}
+
+	a.budget.drop(budgetMaxDuration, len(a.queue))
+	a.dropQueued()
+	a.closeJobsIfNeeded()
 }
 
 func (a *aggregator) closeJobsIfNeeded() {
@@ -292,31 +413,67 @@ func (a *aggregator) onResult(ctx context.Context, result pageResult) {
 }
 
 func (a *aggregator) handleResult(ctx context.Context, result pageResult) {
-	a.pendingPages[result.job.seq] = result.page
+	if result.skipped {
+		// The job waited in the channel past MaxDuration; keep its slot in commit order.
+		a.pendingPages[result.job.seq] = nil
+		a.flushCommitted()
+		a.budget.drop(budgetMaxDuration, 1)
+
+		return
+	}
+
+	if result.mergeInto != "" {
+		a.pendingPages[result.job.seq] = nil
+		a.flushCommitted()
+		a.addAlias(result.mergeInto, result.page)
+		a.state.links[a.normalizer.Key(result.job.url)] = []string{result.mergeInto}
+
+		return
+	}
+
+	a.recordReferrers(result)
+	a.pendingPages[result.job.seq] = &result.page
 	a.flushCommitted()
 
-	if result.job.depth == 0 && result.err != nil && a.state.analysisErr == nil {
+	if result.job.source == sourceRoot && result.err != nil && a.state.analysisErr == nil {
 		a.state.analysisErr = result.err
 	}
 
+	a.recordLinks(result)
+
 	nextDepth := result.job.depth + 1
 	if nextDepth >= a.maxDepth {
 		return
 	}
 
 	for _, link := range result.links {
-		if !urlutil.SameOrigin(a.baseURL, link) {
+		if !a.site.allows(link) {
 			continue
 		}
 
 		a.enqueue(ctx, crawlJob{
 			url:          link,
 			depth:        nextDepth,
+			source:       sourceLink,
 			discoveredAt: a.clock.Now(),
 		})
 	}
 }
 
+// recordLinks adds the on-site links of a page to the link graph, under the page URL and
+// its final URL after redirects.
+func (a *aggregator) recordLinks(result pageResult) {
+	keys := make([]string, 0, len(result.links))
+	for _, link := range result.links {
+		if a.site.sameSite(link) {
+			keys = append(keys, a.normalizer.Key(link))
+		}
+	}
+
+	a.state.links[a.normalizer.Key(result.job.url)] = keys
+	a.state.links[a.normalizer.Key(pageBaseURL(result.page))] = keys
+}
+
 func (a *aggregator) flushCommitted() {
 	for {
 		page, ok := a.pendingPages[a.nextCommit]
@@ -324,16 +481,56 @@ func (a *aggregator) flushCommitted() {
 			return
 		}
 
-		a.report.Pages = append(a.report.Pages, page)
+		if page != nil {
+			a.commitPage(*page)
+		}
 		delete(a.pendingPages, a.nextCommit)
 		a.nextCommit++
 	}
 }
 
+// commitPage adds a page to the report in commit order. A page that ended at the same final
+// URL as an earlier one is not listed again; it is merged into the earlier page.
+func (a *aggregator) commitPage(page Page) {
+	key := finalPageKey(page, a.normalizer)
+	if key == "" {
+		a.report.Pages = append(a.report.Pages, page)
+
+		return
+	}
+
+	if idx, ok := a.finalPages[key]; ok {
+		a.mergePage(&a.report.Pages[idx], page)
+
+		return
+	}
+
+	for _, alias := range a.aliases[key] {
+		page.Aliases = append(page.Aliases, alias.URL)
+	}
+
+	delete(a.aliases, key)
+
+	a.finalPages[key] = len(a.report.Pages)
+	a.report.Pages = append(a.report.Pages, page)
+}
+
 func (a *analyzer) processJob(ctx context.Context, job crawlJob) pageResult {
 	page := newPage(job.url, job.depth, job.discoveredAt)
+	page.Source = a.pageSource(job)
+
+	if key := a.normalizer.Key(job.url); !a.claims.claim(key) {
+		return pageResult{job: job, page: page, mergeInto: key}
+	}
+
+	if !a.robots.allowed(ctx, job.url) {
+		return blockedPageResult(job, page)
+	}
+
 	result, err := a.fetchWithCache(ctx, job.url)
 	page.HTTPStatus = result.StatusCode
+	page.Truncated = result.Truncated
+	page.Redirect = a.redirects.describe(job.url, result, err)
 
 	if err != nil || result.StatusCode >= http.StatusBadRequest {
 		page.Status = statusError
@@ -348,18 +545,34 @@ func (a *analyzer) processJob(ctx context.Context, job crawlJob) pageResult {
 		}
 	}
 
-	parsed, parseErr := parser.ParseHTML(result.Body)
-	if parseErr != nil {
-		page.Status = statusError
-		page.Error = fmt.Sprintf("parse html: %v", parseErr)
-		page.BrokenLinks = nil
-		page.Assets = nil
+	a.applyRobotsHeader(&page, result.Header)
 
-		return pageResult{
-			job:  job,
-			page: page,
-			err:  fmt.Errorf("parse html: %w", parseErr),
-		}
+	if redirectedOffSite(page) {
+		page.Status = statusOK
+
+		return pageResult{job: job, page: page}
+	}
+
+	if key := finalPageKey(page, a.normalizer); key != a.normalizer.Key(job.url) && !a.claims.claim(key) {
+		page.Status = statusOK
+
+		return pageResult{job: job, page: page, mergeInto: key}
+	}
+
+	page.ContentType = mediatype.Detect(result.Header.Get("Content-Type"), result.Body)
+	if !mediatype.IsHTML(page.ContentType) {
+		return nonHTMLPageResult(job, page, result)
+	}
+
+	body, encoding, decodeErr := mediatype.ToUTF8(result.Body, result.Header.Get("Content-Type"), result.Truncated)
+	page.Encoding = encoding
+	if decodeErr != nil {
+		return parseErrorResult(job, page, decodeErr)
+	}
+
+	parsed, parseErr := parser.ParseHTML(body)
+	if parseErr != nil {
+		return parseErrorResult(job, page, parseErr)
 	}
 
 	page.Status = statusOK
@@ -371,33 +584,58 @@ func (a *analyzer) processJob(ctx context.Context, job crawlJob) pageResult {
 		HasH1:          parsed.SEO.HasH1,
 	}
 
+	a.applyRobotsMeta(&page, parsed.Robots)
+	a.recordAnchors(page, parsed.Anchors)
+
 	brokenLinks := []BrokenLink{}
 	pageLinks := []string{}
 	if job.depth < a.maxDepth {
-		brokenLinks, pageLinks = a.checkLinks(ctx, job, parsed.Links)
+		brokenLinks, pageLinks = a.checkLinks(ctx, job.depth, page, parsed)
 	}
-	page.BrokenLinks = dedupBrokenLinks(brokenLinks)
-	page.Assets = a.collectAssets(ctx, job.url, parsed.Assets)
+	pageLinks = a.followedLinks(page, parsed.FollowLinks, pageLinks)
+	page.BrokenLinks = dedupBrokenLinks(brokenLinks, brokenLinkNormalizer(a.normalizer))
+	page.BrokenAnchors = a.checkAnchors(ctx, job.depth, pageBaseURL(page), parsed.Links)
+	page.Assets = a.collectAssets(ctx, pageBaseURL(page), parsed.Assets)
 
 	return pageResult{
-		job:   job,
-		page:  page,
-		links: pageLinks,
+		job:       job,
+		page:      page,
+		links:     pageLinks,
+		referrers: a.brokenLinkReferrers(page, parsed.LinkRefs),
 	}
 }
 
-func (a *analyzer) checkLinks(ctx context.Context, job crawlJob, links []string) ([]BrokenLink, []string) {
-	resolved := a.resolveLinks(job.url, links)
+func (a *analyzer) checkLinks(ctx context.Context, depth int, page Page, parsed parser.ParseResult) ([]BrokenLink, []string) {
+	resolved := a.resolveLinks(pageBaseURL(page), parsed.Links)
 	if len(resolved) == 0 {
 		return []BrokenLink{}, []string{}
 	}
 
-	results, processed := a.runLinkChecks(ctx, resolved)
+	crawled := a.crawledLinks(depth, page, parsed.FollowLinks, resolved)
+	results, processed := a.runLinkChecks(ctx, resolved, crawled)
 
-	return buildLinkResults(results, processed)
+	return buildLinkResults(results, processed, brokenLinkNormalizer(a.normalizer))
 }
 
-func (a *analyzer) runLinkChecks(ctx context.Context, resolved []string) ([]linkCheck, []bool) {
+// crawledLinks returns the keys of the links a page at depth would have queued: links that
+// are followed, inside the crawl scope and above the maximum depth. Budgets, traps and pages
+// queued before are only known to the aggregator and are not taken into account.
+func (a *analyzer) crawledLinks(depth int, page Page, followHrefs []string, links []string) map[string]bool {
+	crawled := map[string]bool{}
+	if depth+1 >= a.maxDepth {
+		return crawled
+	}
+
+	for _, link := range a.followedLinks(page, followHrefs, links) {
+		if a.site.allows(link) {
+			crawled[a.normalizer.Key(link)] = true
+		}
+	}
+
+	return crawled
+}
+
+func (a *analyzer) runLinkChecks(ctx context.Context, resolved []string, crawled map[string]bool) ([]linkCheck, []bool) {
 	results := make([]linkCheck, len(resolved))
 	processed := make([]bool, len(resolved))
 
@@ -409,12 +647,21 @@ func (a *analyzer) runLinkChecks(ctx context.Context, resolved []string) ([]link
 	sent := 0
 feedLoop:
 	for idx, absoluteURL := range resolved {
+		checker := a.linkCheckerFor(absoluteURL)
+		if checker == nil {
+			results[idx] = linkCheck{url: absoluteURL}
+			processed[idx] = true
+
+			continue
+		}
+
 		select {
 		case <-ctx.Done():
 			break feedLoop
-		case a.linkCheck.jobs <- linkCheckJob{
+		case checker.jobs <- linkCheckJob{
 			idx:      idx,
 			url:      absoluteURL,
+			crawled:  crawled[a.normalizer.Key(absoluteURL)],
 			resultCh: resultCh,
 		}:
 			sent++
@@ -459,7 +706,11 @@ func linkCheckPoolSize(opts Options) int {
 	return workerCount
 }
 
-func buildLinkResults(results []linkCheck, processed []bool) ([]BrokenLink, []string) {
+func buildLinkResults(
+	results []linkCheck,
+	processed []bool,
+	normalizer urlutil.Normalizer,
+) ([]BrokenLink, []string) {
 	if len(processed) > len(results) {
 		processed = processed[:len(results)]
 	}
@@ -474,12 +725,12 @@ func buildLinkResults(results []linkCheck, processed []bool) ([]BrokenLink, []st
 		}
 
 		if res.broken {
-			key := res.url
-			if key == "" {
-				key = res.link.URL
+			linkURL := res.url
+			if linkURL == "" {
+				linkURL = res.link.URL
 			}
-			key = canonicalBrokenURL(key)
 
+			key := normalizer.Key(linkURL)
 			if seenBroken[key] {
 				continue
 			}
@@ -487,7 +738,7 @@ func buildLinkResults(results []linkCheck, processed []bool) ([]BrokenLink, []st
 			seenBroken[key] = true
 
 			broken := res.link
-			broken.URL = key
+			broken.URL = normalizer.Normalize(linkURL)
 			brokenLinks = append(brokenLinks, broken)
 
 			continue
@@ -499,14 +750,8 @@ func buildLinkResults(results []linkCheck, processed []bool) ([]BrokenLink, []st
 	return brokenLinks, crawlLinks
 }
 
-func dedupBrokenLinks(links []BrokenLink) []BrokenLink {
-	if len(links) < 2 {
-		if len(links) == 1 {
-			out := links[0]
-			out.URL = canonicalBrokenURL(out.URL)
-			return []BrokenLink{out}
-		}
-
+func dedupBrokenLinks(links []BrokenLink, normalizer urlutil.Normalizer) []BrokenLink {
+	if len(links) == 0 {
 		return links
 	}
 
@@ -514,73 +759,20 @@ func dedupBrokenLinks(links []BrokenLink) []BrokenLink {
 	seen := make(map[string]bool, len(links))
 
 	for _, link := range links {
-		key := canonicalBrokenURL(link.URL)
+		key := normalizer.Key(link.URL)
 		if seen[key] {
 			continue
 		}
 
 		seen[key] = true
 		out := link
-		out.URL = key
+		out.URL = normalizer.Normalize(link.URL)
 		unique = append(unique, out)
 	}
 
 	return unique
 }
 
-func canonicalBrokenURL(raw string) string {
-	if raw == "" {
-		return ""
-	}
-
-	parsed, err := url.Parse(raw)
-	if err != nil {
-		return raw
-	}
-
-	parsed.Fragment = ""
-	parsed.Scheme = strings.ToLower(parsed.Scheme)
-
-	host := strings.ToLower(parsed.Hostname())
-	port := parsed.Port()
-
-	if isDefaultPortForScheme(parsed.Scheme, port) {
-		port = ""
-	}
-
-	if port == "" {
-		parsed.Host = host
-	} else {
-		parsed.Host = net.JoinHostPort(host, port)
-	}
-
-	if parsed.Path == "/" {
-		parsed.Path = ""
-	} else if parsed.Path != "" {
-		parsed.Path = strings.TrimRight(parsed.Path, "/")
-	}
-
-	parsed.RawPath = ""
-	if parsed.RawQuery == "" {
-		parsed.ForceQuery = false
-	}
-
-	return parsed.String()
-}
-
-func isDefaultPortForScheme(scheme string, port string) bool {
-	if port == "" || scheme == "" {
-		return false
-	}
-
-	defaultPort, err := net.LookupPort("tcp", scheme)
-	if err != nil {
-		return false
-	}
-
-	return port == strconv.Itoa(defaultPort)
-}
-
 func (a *analyzer) resolveLinks(pageURL string, links []string) []string {
 	base, err := url.Parse(pageURL)
 	if err != nil {
@@ -591,24 +783,46 @@ func (a *analyzer) resolveLinks(pageURL string, links []string) []string {
 	seen := map[string]bool{}
 
 	for _, link := range links {
-		absoluteURL, ok := urlutil.Resolve(base, link)
+		absoluteURL, ok := a.normalizer.Resolve(base, link)
 		if !ok {
 			continue
 		}
 
-		if seen[absoluteURL] {
+		key := a.normalizer.Key(absoluteURL)
+		if seen[key] {
 			continue
 		}
 
-		seen[absoluteURL] = true
+		seen[key] = true
 		resolved = append(resolved, absoluteURL)
 	}
 
 	return resolved
 }
 
-func (a *analyzer) checkBrokenLink(ctx context.Context, absoluteURL string) (BrokenLink, bool) {
-	result, err := a.fetchWithCache(ctx, absoluteURL)
+// checkBrokenLink checks a link on the crawled site. A link that is crawled goes through the
+// fetch cache, so the crawl reuses the response; with HeadChecks, other links are checked
+// with HEAD first.
+func (a *analyzer) checkBrokenLink(ctx context.Context, absoluteURL string, crawled bool) (BrokenLink, bool) {
+	if crawled || !a.options.HeadChecks {
+		return a.brokenLinkResult(ctx, absoluteURL, a.fetchWithCache)
+	}
+
+	return a.brokenLinkResult(ctx, absoluteURL, a.headWithCache)
+}
+
+// brokenLinkResult fetches a link with fetch and describes it if it is broken.
+// Links robots.txt disallows are not fetched and never broken.
+func (a *analyzer) brokenLinkResult(
+	ctx context.Context,
+	absoluteURL string,
+	fetch func(ctx context.Context, absoluteURL string) (fetcher.Result, error),
+) (BrokenLink, bool) {
+	if !a.robots.allowed(ctx, absoluteURL) {
+		return BrokenLink{}, false
+	}
+
+	result, err := fetch(ctx, absoluteURL)
 
 	broken := err != nil || result.StatusCode >= http.StatusBadRequest
 	if !broken {
@@ -619,13 +833,24 @@ func (a *analyzer) checkBrokenLink(ctx context.Context, absoluteURL string) (Bro
 		URL:        absoluteURL,
 		StatusCode: result.StatusCode,
 		Error:      errorString(err, result.StatusCode),
+		Redirect:   a.redirects.describe(absoluteURL, result, err),
 	}, true
 }
 
 func (a *analyzer) fetchWithCache(ctx context.Context, absoluteURL string) (fetcher.Result, error) {
+	return a.cachedFetch(ctx, absoluteURL, func(ctx context.Context) (fetcher.Result, error) {
+		return a.fetch.Fetch(ctx, absoluteURL)
+	})
+}
+
+func (a *analyzer) cachedFetch(
+	ctx context.Context,
+	key string,
+	fetch func(ctx context.Context) (fetcher.Result, error),
+) (fetcher.Result, error) {
 	a.fetchMu.Lock()
 
-	if cached, ok := a.fetchCache[absoluteURL]; ok {
+	if cached, ok := a.fetchCache[key]; ok {
 		ready := cached.ready
 		a.fetchMu.Unlock()
 
@@ -638,12 +863,12 @@ func (a *analyzer) fetchWithCache(ctx context.Context, absoluteURL string) (fetc
 	}
 
 	entry := &fetchCacheEntry{ready: make(chan struct{})}
-	a.fetchCache[absoluteURL] = entry
+	a.fetchCache[key] = entry
 	a.fetchMu.Unlock()
 
 	if !a.acquireFetch(ctx) {
 		a.fetchMu.Lock()
-		delete(a.fetchCache, absoluteURL)
+		delete(a.fetchCache, key)
 		a.fetchMu.Unlock()
 
 		entry.result = fetcher.Result{}
@@ -654,7 +879,7 @@ func (a *analyzer) fetchWithCache(ctx context.Context, absoluteURL string) (fetc
 	}
 	defer a.releaseFetch()
 
-	result, err := a.fetch.Fetch(ctx, absoluteURL)
+	result, err := fetch(ctx)
 	entry.result = result
 	entry.err = err
 	close(entry.ready)
@@ -672,7 +897,7 @@ func (a *analyzer) collectAssets(ctx context.Context, pageURL string, assets []p
 	}
 
 	for _, assetRef := range assets {
-		absoluteURL, ok := urlutil.Resolve(base, assetRef.URL)
+		absoluteURL, ok := a.normalizer.Resolve(base, assetRef.URL)
 		if !ok {
 			continue
 		}
@@ -731,7 +956,7 @@ func (a *analyzer) getAsset(ctx context.Context, absoluteURL string, assetType s
 	}
 	defer a.releaseFetch()
 
-	entry.result = fetchAssetResult(ctx, a.fetch, absoluteURL)
+	entry.result = fetchAssetResult(ctx, a.assetFetch, absoluteURL, a.options.HeadChecks, a.redirects)
 	close(entry.ready)
 
 	return buildAssetFromResult(absoluteURL, assetType, entry.result)
@@ -744,6 +969,8 @@ func buildAssetFromResult(absoluteURL string, assetType string, result assetFetc
 		StatusCode: result.statusCode,
 		SizeBytes:  result.sizeBytes,
 		Error:      result.err,
+		Truncated:  result.truncated,
+		Redirect:   result.redirect,
 	}
 }
 
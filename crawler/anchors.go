@@ -0,0 +1,142 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"code/internal/fetcher"
+	"code/internal/mediatype"
+	"code/internal/parser"
+)
+
+// recordAnchors remembers the anchors of a parsed page under its URL and its final URL,
+// so links to them are checked without parsing the page again.
+func (a *analyzer) recordAnchors(page Page, anchors []string) {
+	if !a.options.CheckAnchors {
+		return
+	}
+
+	set := anchorSet(anchors)
+
+	a.anchorMu.Lock()
+	defer a.anchorMu.Unlock()
+
+	a.anchors[a.normalizer.Key(page.URL)] = set
+	a.anchors[a.normalizer.Key(pageBaseURL(page))] = set
+}
+
+// checkAnchors returns the links of a page whose fragment matches no id or name on the
+// target page. Only links on the site that the link check mode fetches are checked, and
+// targets that are broken or not HTML are left to the broken link check.
+func (a *analyzer) checkAnchors(ctx context.Context, depth int, pageURL string, links []string) []BrokenAnchor {
+	if !a.options.CheckAnchors || depth >= a.maxDepth {
+		return nil
+	}
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+
+	var broken []BrokenAnchor
+	seen := map[string]bool{}
+
+	for _, link := range links {
+		target, fragment, ok := a.normalizer.ResolveFragment(base, link)
+		if !ok || ignoredFragment(fragment) || !a.checksAnchor(target) {
+			continue
+		}
+
+		key := a.normalizer.Key(target) + "#" + fragment
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+
+		anchors, ok := a.anchorsOf(ctx, target)
+		if ok && !anchors[fragment] {
+			broken = append(broken, BrokenAnchor{URL: target, Fragment: fragment})
+		}
+	}
+
+	return broken
+}
+
+func (a *analyzer) checksAnchor(target string) bool {
+	return a.site.sameSite(target) && a.site.checks(target) && a.site.fetchesLink(target)
+}
+
+// anchorsOf returns the anchors of the page at target, fetching it through the fetch cache
+// when it was not parsed yet. ok is false when the page has no usable HTML.
+func (a *analyzer) anchorsOf(ctx context.Context, target string) (map[string]bool, bool) {
+	key := a.normalizer.Key(target)
+
+	a.anchorMu.Lock()
+	anchors, ok := a.anchors[key]
+	a.anchorMu.Unlock()
+
+	if ok {
+		return anchors, true
+	}
+
+	if !a.robots.allowed(ctx, target) {
+		return nil, false
+	}
+
+	result, err := a.fetchWithCache(ctx, target)
+	if err != nil || result.StatusCode >= http.StatusBadRequest {
+		return nil, false
+	}
+
+	anchors, ok = parseAnchors(result)
+	if !ok {
+		return nil, false
+	}
+
+	a.anchorMu.Lock()
+	a.anchors[key] = anchors
+	a.anchorMu.Unlock()
+
+	return anchors, true
+}
+
+func parseAnchors(result fetcher.Result) (map[string]bool, bool) {
+	contentType := result.Header.Get("Content-Type")
+	if !mediatype.IsHTML(mediatype.Detect(contentType, result.Body)) {
+		return nil, false
+	}
+
+	body, _, err := mediatype.ToUTF8(result.Body, contentType, result.Truncated)
+	if err != nil {
+		return nil, false
+	}
+
+	parsed, err := parser.ParseHTML(body)
+	if err != nil {
+		return nil, false
+	}
+
+	return anchorSet(parsed.Anchors), true
+}
+
+func anchorSet(anchors []string) map[string]bool {
+	set := make(map[string]bool, len(anchors))
+	for _, anchor := range anchors {
+		set[anchor] = true
+	}
+
+	return set
+}
+
+// ignoredFragment reports whether a fragment is valid without a matching element: "top"
+// scrolls to the start of the page, ":~:" starts a text fragment, and "!" or "/" start
+// client-side routes.
+func ignoredFragment(fragment string) bool {
+	return strings.EqualFold(fragment, "top") ||
+		strings.HasPrefix(fragment, ":~:") ||
+		strings.HasPrefix(fragment, "!") ||
+		strings.HasPrefix(fragment, "/")
+}
@@ -0,0 +1,78 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func anchorFixtureClient(t *testing.T) (*http.Client, *callTracker) {
+	t.Helper()
+
+	html := http.Header{"Content-Type": []string{"text/html"}}
+
+	return newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body>
+				<h2 id="intro">Intro</h2>
+				<a href="#intro">ok</a>
+				<a href="#missing">missing here</a>
+				<a href="/docs#install">ok there</a>
+				<a href="/docs#gone">missing there</a>
+				<a href="/docs#top">top</a>
+				<a href="/missing#x">dead page</a>
+				<a href="/file.pdf#page=2">pdf</a>
+				<a href="https://other.test/#nowhere">other site</a>
+			</body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "example.com", "/docs"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body><a name="install"></a></body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "example.com", "/file.pdf"): func(req *http.Request) (*http.Response, error) {
+			header := http.Header{"Content-Type": []string{"application/pdf"}}
+			return responseForRequest(req, http.StatusOK, "%PDF-1.4", header), nil
+		},
+		routeID("https", "other.test", "/"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, `<html></html>`, html), nil
+		},
+	})
+}
+
+func TestSpec_Anchors_ReportedSeparatelyFromBrokenLinks(t *testing.T) {
+	t.Parallel()
+
+	// At depth 1 /docs is only link-checked; at depth 2 it is crawled as well.
+	for _, depth := range []int{1, 2} {
+		client, tracker := anchorFixtureClient(t)
+		opts := optionsForContract(fixtureBaseURL, depth, 0, client, &testClock{now: fixtureTime})
+		opts.CheckAnchors = true
+
+		report, err := analyzeReport(context.Background(), opts)
+		require.NoError(t, err)
+
+		root := findPageByPath(t, report, "/")
+		require.Equal(t, []BrokenAnchor{
+			{URL: "https://example.com", Fragment: "missing"},
+			{URL: "https://example.com/docs", Fragment: "gone"},
+		}, root.BrokenAnchors, "depth %d", depth)
+		require.Equal(t, []string{"https://example.com/missing"}, brokenLinkURLs(root))
+
+		// The link check already fetched /docs; its anchors come from the same response.
+		require.Equal(t, 1, tracker.countHostPath("example.com", "/docs"))
+	}
+}
+
+func TestSpec_Anchors_NotCheckedByDefault(t *testing.T) {
+	t.Parallel()
+
+	client, _ := anchorFixtureClient(t)
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, &testClock{now: fixtureTime})
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+	require.Nil(t, findPageByPath(t, report, "/").BrokenAnchors)
+}
@@ -0,0 +1,75 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_BodyLimit_ParsesTruncatedPage(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	head := `<html><head><title>Cut</title></head><body><a href="/missing-early"></a>`
+	client, tracker := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := head + strings.Repeat(" ", 1000) + `<a href="/missing-late"></a><img src="/video.mp4"></body></html>`
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+		routeID("https", "example.com", "/video.mp4"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, strings.Repeat("v", 500), nil), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, clock)
+	opts.MaxBodyBytes = int64(len(head))
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	root := findPageByPath(t, report, "/")
+	require.Equal(t, statusOK, root.Status)
+	require.True(t, root.Truncated)
+	require.Equal(t, "Cut", root.SEO.Title)
+	require.Len(t, root.BrokenLinks, 1)
+	require.Equal(t, fixtureBaseURL+"/missing-early", root.BrokenLinks[0].URL)
+	require.Zero(t, tracker.countHostPath("example.com", "/missing-late"))
+	require.Empty(t, root.Assets)
+}
+
+func TestSpec_BodyLimit_MarksTruncatedAsset(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body><img src="/video.mp4"><img src="/logo.png"></body></html>`
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+		routeID("https", "example.com", "/video.mp4"): func(req *http.Request) (*http.Response, error) {
+			return responseWithBody(http.StatusOK, []byte(strings.Repeat("v", 500)), http.Header{}), nil
+		},
+		routeID("https", "example.com", "/logo.png"): func(req *http.Request) (*http.Response, error) {
+			return responseWithBody(http.StatusOK, []byte("png"), http.Header{}), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 0, 0, client, clock)
+	opts.MaxAssetBytes = 100
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	root := findPageByPath(t, report, "/")
+	require.False(t, root.Truncated)
+	require.Len(t, root.Assets, 2)
+
+	video, logo := root.Assets[0], root.Assets[1]
+	require.True(t, video.Truncated)
+	require.Equal(t, int64(100), video.SizeBytes)
+	require.False(t, logo.Truncated)
+	require.Equal(t, int64(3), logo.SizeBytes)
+}
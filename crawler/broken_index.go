@@ -0,0 +1,125 @@
+package crawler
+
+import (
+	"net/url"
+	"slices"
+	"sort"
+
+	"code/internal/parser"
+	"code/internal/urlutil"
+)
+
+// brokenLinkReferrers returns, for each broken link of a page, the link elements on the
+// page that point to it, keyed by the link's normalized key.
+func (a *analyzer) brokenLinkReferrers(page Page, refs []parser.LinkRef) map[string][]LinkReferrer {
+	if len(page.BrokenLinks) == 0 {
+		return nil
+	}
+
+	base, err := url.Parse(pageBaseURL(page))
+	if err != nil {
+		return nil
+	}
+
+	normalizer := brokenLinkNormalizer(a.normalizer)
+
+	broken := make(map[string]bool, len(page.BrokenLinks))
+	for _, link := range page.BrokenLinks {
+		broken[normalizer.Key(link.URL)] = true
+	}
+
+	referrers := make(map[string][]LinkReferrer, len(broken))
+
+	for _, ref := range refs {
+		absoluteURL, ok := normalizer.Resolve(base, ref.URL)
+		if !ok {
+			continue
+		}
+
+		key := normalizer.Key(absoluteURL)
+		referrer := LinkReferrer{Page: page.URL, Text: ref.Text, Tag: ref.Tag}
+
+		if broken[key] && !slices.Contains(referrers[key], referrer) {
+			referrers[key] = append(referrers[key], referrer)
+		}
+	}
+
+	return referrers
+}
+
+// recordReferrers keeps the broken link referrers of a handled page for the site-wide index.
+func (a *aggregator) recordReferrers(result pageResult) {
+	if len(result.referrers) == 0 {
+		return
+	}
+
+	a.referrers[result.page.URL] = result.referrers
+}
+
+// moveReferrers files the referrers recorded for a page merged into another page under that
+// page, which now stands for the document both URLs ended at.
+func (a *aggregator) moveReferrers(from, to string) {
+	moved, ok := a.referrers[from]
+	if !ok {
+		return
+	}
+
+	delete(a.referrers, from)
+
+	target := a.referrers[to]
+	if target == nil {
+		target = make(map[string][]LinkReferrer, len(moved))
+		a.referrers[to] = target
+	}
+
+	for key, refs := range moved {
+		for _, ref := range refs {
+			ref.Page = to
+			if !slices.Contains(target[key], ref) {
+				target[key] = append(target[key], ref)
+			}
+		}
+	}
+}
+
+// buildBrokenLinkIndex lists every broken link of the reported pages once, sorted by URL,
+// with all the places that link to it in page order. Pages merged into another page as
+// aliases are not reported and do not count.
+func buildBrokenLinkIndex(
+	pages []Page,
+	referrers map[string]map[string][]LinkReferrer,
+	normalizer urlutil.Normalizer,
+) []BrokenLinkEntry {
+	index := []BrokenLinkEntry{}
+	byKey := map[string]int{}
+
+	for _, page := range pages {
+		for _, link := range page.BrokenLinks {
+			key := normalizer.Key(link.URL)
+
+			idx, ok := byKey[key]
+			if !ok {
+				idx = len(index)
+				byKey[key] = idx
+				index = append(index, BrokenLinkEntry{
+					URL:        link.URL,
+					StatusCode: link.StatusCode,
+					Error:      link.Error,
+					Referrers:  []LinkReferrer{},
+				})
+			}
+
+			index[idx].Referrers = append(index[idx].Referrers, referrers[page.URL][key]...)
+		}
+	}
+
+	if len(index) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(index, func(i, j int) bool {
+		return index[i].URL < index[j].URL
+	})
+
+	return index
+}
@@ -0,0 +1,73 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_BrokenLinkIndex_ListsEveryReferrer(t *testing.T) {
+	t.Parallel()
+
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body>
+				<a href="/missing">Missing</a>
+				<a href="/missing"><img src="/x.png" alt="Dead icon"></a>
+				<a href="/missing">Missing</a>
+				<a href="/a">A</a>
+			</body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "example.com", "/a"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body>
+				<a href="/missing/">Again</a>
+				<map><area href="https://other.test/gone" alt="Map"></map>
+			</body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 2, 0, client, &testClock{now: fixtureTime})
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.Equal(t, []BrokenLinkEntry{
+		{
+			URL:        "https://example.com/missing",
+			StatusCode: http.StatusNotFound,
+			Error:      "Not Found",
+			Referrers: []LinkReferrer{
+				{Page: "https://example.com", Text: "Missing", Tag: "a"},
+				{Page: "https://example.com", Text: "Dead icon", Tag: "a"},
+				{Page: "https://example.com/a", Text: "Again", Tag: "a"},
+			},
+		},
+		{
+			URL:        "https://other.test/gone",
+			StatusCode: http.StatusNotFound,
+			Error:      "Not Found",
+			Referrers: []LinkReferrer{
+				{Page: "https://example.com/a", Text: "Map", Tag: "area"},
+			},
+		},
+	}, report.BrokenLinks)
+}
+
+func TestSpec_BrokenLinkIndex_OmittedWithoutBrokenLinks(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, `<html></html>`, nil), nil
+		},
+	})
+
+	report, err := analyzeReport(context.Background(), optionsForContract(fixtureBaseURL, 1, 0, client, &testClock{now: fixtureTime}))
+	require.NoError(t, err)
+	require.Nil(t, report.BrokenLinks)
+}
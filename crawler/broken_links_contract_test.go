@@ -11,7 +11,7 @@ import (
 	"time"
 
 	"code/internal/fetcher"
-	"code/internal/limiter"
+	"code/internal/urlutil"
 
 	"github.com/stretchr/testify/require"
 )
@@ -251,22 +251,21 @@ func TestSpec_BrokenLinks_ChildErrorPageNullCollections(t *testing.T) {
 	})
 
 	opts := optionsForContract(fixtureBaseURL, 1, 0, client, clock)
-	baseURL, err := parseRootURL(opts.URL)
+	baseURL, err := parseRootURL(opts.URL, urlutil.Normalizer{})
 	require.NoError(t, err)
 
-	rateLimiter := limiter.NewWithTimer(rateInterval(opts), opts.Clock)
 	pageFetcher := fetcher.New(
 		opts.HTTPClient,
 		opts.Timeout,
 		opts.UserAgent,
-		rateLimiter,
-		opts.Retries,
-		opts.Delay,
+		newLimiterRegistry(opts),
+		nil,
+		pageRetryPolicy(opts),
 		opts.Clock,
 	)
 
 	report := newReport(opts)
-	a := newAnalyzer(opts, baseURL, pageFetcher, &report)
+	a := newAnalyzer(opts, baseURL, pageFetcher, nil, &report)
 
 	result := a.processJob(context.Background(), crawlJob{
 		url:          fixtureBaseURL,
@@ -11,6 +11,41 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestSpec_BrokenLinks_ImageMapAreasAreCrawledAndChecked(t *testing.T) {
+	t.Parallel()
+
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	client, calls := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body>
+				<img src="/plan.png" usemap="#plan">
+				<map name="plan">
+					<area href="/floor" alt="Floor">
+					<area href="/closed" alt="Closed wing">
+				</map>
+			</body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "example.com", "/plan.png"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "png", http.Header{"Content-Type": []string{"image/png"}}), nil
+		},
+		routeID("https", "example.com", "/floor"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "<html><body>floor</body></html>", html), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 2, 0, client, &testClock{now: fixtureTime})
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.ElementsMatch(t, []string{"https://example.com", "https://example.com/floor"}, pageURLs(report))
+	require.Equal(t, 1, calls.countHostPath("example.com", "/floor"))
+
+	root := findPageByPath(t, report, "/")
+	require.Equal(t, []string{"https://example.com/closed"}, brokenLinkURLs(root))
+}
+
 func TestSpec_BrokenLinks_IncludeOnlyBroken_AndUseAbsoluteURL(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,141 @@
+package crawler
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"code/internal/limiter"
+)
+
+const (
+	budgetMaxPages             = "max_pages"
+	budgetMaxDuration          = "max_duration"
+	budgetMaxURLsPerPathPrefix = "max_urls_per_path_prefix"
+)
+
+// crawlBudget caps how much of a site one crawl covers. Its counters belong to the
+// aggregator goroutine; expired only reads the deadline and is safe to call from workers.
+type crawlBudget struct {
+	clock        limiter.Timer
+	deadline     time.Time
+	maxPages     int
+	maxPerPrefix int
+	pages        int
+	perPrefix    map[string]int
+	prefixesHit  map[string]bool
+	exhausted    string
+	queued       int
+}
+
+func newCrawlBudget(opts Options, start time.Time) *crawlBudget {
+	budget := &crawlBudget{
+		clock:        opts.Clock,
+		maxPages:     opts.MaxPages,
+		maxPerPrefix: opts.MaxURLsPerPathPrefix,
+		perPrefix:    map[string]int{},
+		prefixesHit:  map[string]bool{},
+	}
+
+	if opts.MaxDuration > 0 {
+		budget.deadline = start.Add(opts.MaxDuration)
+	}
+
+	return budget
+}
+
+// expired reports whether MaxDuration has passed since the crawl started.
+func (b *crawlBudget) expired() bool {
+	return !b.deadline.IsZero() && !b.clock.Now().Before(b.deadline)
+}
+
+// admit reports whether rawURL may be queued and counts it against the budgets.
+// A rejected URL is counted as left in the queue.
+func (b *crawlBudget) admit(rawURL string) bool {
+	if reason := b.globalLimit(); reason != "" {
+		b.drop(reason, 1)
+
+		return false
+	}
+
+	if b.maxPerPrefix > 0 {
+		prefix := pathPrefix(rawURL)
+		if b.perPrefix[prefix] >= b.maxPerPrefix {
+			b.prefixesHit[prefix] = true
+			b.queued++
+
+			return false
+		}
+
+		b.perPrefix[prefix]++
+	}
+
+	b.pages++
+
+	return true
+}
+
+// globalLimit returns the budget that stops the whole crawl, or "" while none has run out.
+func (b *crawlBudget) globalLimit() string {
+	switch {
+	case b.exhausted != "":
+		return b.exhausted
+	case b.expired():
+		return budgetMaxDuration
+	case b.maxPages > 0 && b.pages >= b.maxPages:
+		return budgetMaxPages
+	default:
+		return ""
+	}
+}
+
+// drop records count discovered URLs that were not crawled because reason ran out.
+func (b *crawlBudget) drop(reason string, count int) {
+	if b.exhausted == "" {
+		b.exhausted = reason
+	}
+
+	b.queued += count
+}
+
+// summary returns the report entry, or nil when no budget ran out.
+func (b *crawlBudget) summary() *CrawlBudget {
+	if b.exhausted == "" && len(b.prefixesHit) == 0 {
+		return nil
+	}
+
+	summary := &CrawlBudget{
+		Exhausted: b.exhausted,
+		Queued:    b.queued,
+	}
+
+	if summary.Exhausted == "" {
+		summary.Exhausted = budgetMaxURLsPerPathPrefix
+	}
+
+	for prefix := range b.prefixesHit {
+		summary.PathPrefixes = append(summary.PathPrefixes, prefix)
+	}
+
+	sort.Strings(summary.PathPrefixes)
+
+	return summary
+}
+
+// pathPrefix returns the directory of rawURL: its origin and path up to the last "/".
+func pathPrefix(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	dir := parsed.EscapedPath()
+	if idx := strings.LastIndex(dir, "/"); idx >= 0 {
+		dir = dir[:idx+1]
+	} else {
+		dir = "/"
+	}
+
+	return parsed.Scheme + "://" + parsed.Host + dir
+}
@@ -0,0 +1,170 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"code/internal/fetcher"
+	"code/internal/urlutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+func budgetFixtureClient(t *testing.T, paths []string, onRoot func()) *http.Client {
+	t.Helper()
+
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	page := func(req *http.Request) (*http.Response, error) {
+		return responseForRequest(req, http.StatusOK, `<html></html>`, html), nil
+	}
+
+	var links strings.Builder
+	routes := map[string]roundTripResponder{}
+	for _, path := range paths {
+		fmt.Fprintf(&links, `<a href="%s"></a>`, path)
+		routes[routeID("https", "example.com", path)] = page
+	}
+
+	routes[routeID("https", "example.com", "/")] = func(req *http.Request) (*http.Response, error) {
+		if onRoot != nil {
+			onRoot()
+		}
+
+		return responseForRequest(req, http.StatusOK, `<html><body>`+links.String()+`</body></html>`, html), nil
+	}
+
+	client, _ := newTrackedClient(t, routes)
+
+	return client
+}
+
+func pageURLs(report Report) []string {
+	urls := make([]string, 0, len(report.Pages))
+	for _, page := range report.Pages {
+		urls = append(urls, page.URL)
+	}
+
+	return urls
+}
+
+func TestSpec_Budget_MaxPagesStopsQueuing(t *testing.T) {
+	t.Parallel()
+
+	client := budgetFixtureClient(t, []string{"/a", "/b", "/c", "/d", "/e"}, nil)
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, &testClock{now: fixtureTime})
+	opts.MaxPages = 3
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"https://example.com", "https://example.com/a", "https://example.com/b"}, pageURLs(report))
+	require.Equal(t, &CrawlBudget{Exhausted: budgetMaxPages, Queued: 3}, report.Budget)
+}
+
+func TestSpec_Budget_MaxURLsPerPathPrefix(t *testing.T) {
+	t.Parallel()
+
+	client := budgetFixtureClient(t, []string{"/products/1", "/products/2", "/products/3", "/products/4", "/about"}, nil)
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, &testClock{now: fixtureTime})
+	opts.MaxURLsPerPathPrefix = 2
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.ElementsMatch(t, []string{
+		"https://example.com",
+		"https://example.com/products/1",
+		"https://example.com/products/2",
+		"https://example.com/about",
+	}, pageURLs(report))
+	require.Equal(t, &CrawlBudget{
+		Exhausted:    budgetMaxURLsPerPathPrefix,
+		Queued:       2,
+		PathPrefixes: []string{"https://example.com/products/"},
+	}, report.Budget)
+}
+
+func TestSpec_Budget_MaxDurationKeepsFinishedPages(t *testing.T) {
+	t.Parallel()
+
+	clock := &rateClock{now: fixtureTime}
+	client := budgetFixtureClient(t, []string{"/a", "/b"}, func() {
+		_ = clock.Sleep(context.Background(), time.Minute)
+	})
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, nil)
+	opts.Clock = clock
+	opts.MaxDuration = 30 * time.Second
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"https://example.com"}, pageURLs(report))
+	require.Equal(t, statusOK, report.Pages[0].Status)
+	require.Equal(t, &CrawlBudget{Exhausted: budgetMaxDuration, Queued: 2}, report.Budget)
+}
+
+func TestSpec_Budget_MaxDurationSkipsQueuedSitemapSeeds(t *testing.T) {
+	t.Parallel()
+
+	clock := &rateClock{now: fixtureTime}
+	client := budgetFixtureClient(t, nil, nil)
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, nil)
+	opts.Clock = clock
+	opts.MaxDuration = 30 * time.Second
+
+	baseURL, err := parseRootURL(opts.URL, urlutil.Normalizer{})
+	require.NoError(t, err)
+
+	report := newReport(opts)
+	a := newAnalyzer(opts, baseURL, fetcher.New(client, time.Second, "", nil, nil, fetcher.RetryPolicy{}, opts.Clock), nil, &report)
+	a.budget = newCrawlBudget(opts, clock.Now())
+	_ = clock.Sleep(context.Background(), time.Minute)
+
+	jobs := make(chan crawlJob, 2)
+	jobs <- crawlJob{url: fixtureBaseURL, source: sourceRoot}
+	jobs <- crawlJob{url: fixtureBaseURL + "/listed", depth: 1, source: sourceSitemap}
+	close(jobs)
+
+	results := make(chan pageResult, 2)
+	a.worker(context.Background(), jobs, results)
+
+	require.False(t, (<-results).skipped, "the root is fetched even after the deadline")
+	require.True(t, (<-results).skipped)
+}
+
+func TestSpec_Budget_NotReportedWhenUnused(t *testing.T) {
+	t.Parallel()
+
+	client := budgetFixtureClient(t, []string{"/a"}, nil)
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, &testClock{now: fixtureTime})
+	opts.MaxPages = 2
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+	require.Len(t, report.Pages, 2)
+	require.Nil(t, report.Budget)
+}
+
+func TestPathPrefix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "https://example.com", want: "https://example.com/"},
+		{raw: "https://example.com/about", want: "https://example.com/"},
+		{raw: "https://example.com/products/shoes/123?color=red", want: "https://example.com/products/shoes/"},
+		{raw: "https://example.com/products/", want: "https://example.com/products/"},
+	}
+
+	for _, tt := range tests {
+		if got := pathPrefix(tt.raw); got != tt.want {
+			t.Fatalf("pathPrefix(%q) = %q; want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_Charset_DecodesPagesToUTF8(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body><a href="/header"></a><a href="/meta"></a></body></html>`
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+		routeID("https", "example.com", "/header"): func(req *http.Request) (*http.Response, error) {
+			// "Привет" in windows-1251.
+			body := "<html><head><title>\xCF\xF0\xE8\xE2\xE5\xF2</title></head></html>"
+			header := http.Header{"Content-Type": []string{"text/html; charset=windows-1251"}}
+			return responseForRequest(req, http.StatusOK, body, header), nil
+		},
+		routeID("https", "example.com", "/meta"): func(req *http.Request) (*http.Response, error) {
+			body := "<html><head><meta charset=\"iso-8859-1\"><title>Caf\xE9</title></head></html>"
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 2, 0, client, clock)
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	root := findPageByPath(t, report, "/")
+	require.Equal(t, "utf-8", root.Encoding)
+
+	fromHeader := findPageByPath(t, report, "/header")
+	require.Equal(t, "windows-1251", fromHeader.Encoding)
+	require.Equal(t, "Привет", fromHeader.SEO.Title)
+
+	fromMeta := findPageByPath(t, report, "/meta")
+	require.Equal(t, "windows-1252", fromMeta.Encoding)
+	require.Equal(t, "Café", fromMeta.SEO.Title)
+}
@@ -0,0 +1,74 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_CircuitBreaker_FailsFastForDownHost(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	routes := map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body>
+				<a href="https://down.test/1"></a><a href="https://down.test/2"></a>
+				<a href="https://down.test/3"></a><a href="https://down.test/4"></a>
+				<a href="https://down.test/5"></a>
+			</body></html>`
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+	}
+	for _, path := range []string{"/1", "/2", "/3", "/4", "/5"} {
+		routes[routeID("https", "down.test", path)] = func(*http.Request) (*http.Response, error) {
+			return nil, errors.New("dial tcp: connection reset")
+		}
+	}
+	client, tracker := newTrackedClient(t, routes)
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, clock)
+	opts.CircuitBreakerThreshold = 2
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	requests := 0
+	for _, path := range []string{"/1", "/2", "/3", "/4", "/5"} {
+		requests += tracker.countHostPath("down.test", path)
+	}
+	require.Less(t, requests, 5)
+
+	root := findPageByPath(t, report, "/")
+	require.Len(t, root.BrokenLinks, 5)
+
+	open := 0
+	for _, link := range root.BrokenLinks {
+		if strings.Contains(link.Error, "circuit breaker open for down.test") {
+			open++
+		}
+	}
+	require.Equal(t, 5-requests, open)
+
+	require.Len(t, report.CircuitBreakers, 1)
+	require.Equal(t, HostCircuit{Host: "down.test", State: "open", Trips: 1, Rejected: open}, report.CircuitBreakers[0])
+}
+
+func TestSpec_CircuitBreaker_DisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("dial tcp: connection reset")
+		},
+	})
+
+	report, err := analyzeReport(context.Background(), optionsForContract(fixtureBaseURL, 0, 0, client, clock))
+	require.Error(t, err)
+	require.Nil(t, report.CircuitBreakers)
+}
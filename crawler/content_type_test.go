@@ -0,0 +1,113 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_ContentType_ParsesOnlyHTML(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	client, tracker := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body>
+				<a href="/report.pdf"></a><a href="/api.json"></a><a href="/page.xhtml"></a><a href="/sniffed"></a>
+			</body></html>`
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+		routeID("https", "example.com", "/report.pdf"): func(req *http.Request) (*http.Response, error) {
+			header := http.Header{"Content-Type": []string{"application/pdf"}, "Content-Length": []string{"2048"}}
+			return responseForRequest(req, http.StatusOK, `%PDF-1.4 <a href="/from-pdf"></a>`, header), nil
+		},
+		routeID("https", "example.com", "/api.json"): func(req *http.Request) (*http.Response, error) {
+			header := http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}
+			return responseForRequest(req, http.StatusOK, `{"html":"<a href=\"/from-json\"></a>"}`, header), nil
+		},
+		routeID("https", "example.com", "/page.xhtml"): func(req *http.Request) (*http.Response, error) {
+			header := http.Header{"Content-Type": []string{"application/xhtml+xml"}}
+			return responseForRequest(req, http.StatusOK, `<html><head><title>X</title></head></html>`, header), nil
+		},
+		routeID("https", "example.com", "/sniffed"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, `<!DOCTYPE html><html><head><title>S</title></head></html>`, nil), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, clock)
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	pdf := findPageByPath(t, report, "/report.pdf")
+	require.Equal(t, "application/pdf", pdf.ContentType)
+	require.Equal(t, statusOK, pdf.Status)
+	require.Equal(t, int64(2048), pdf.SizeBytes)
+	require.Empty(t, pdf.BrokenLinks)
+
+	jsonPage := findPageByPath(t, report, "/api.json")
+	require.Equal(t, "application/json", jsonPage.ContentType)
+	require.Equal(t, int64(len(`{"html":"<a href=\"/from-json\"></a>"}`)), jsonPage.SizeBytes)
+
+	require.Zero(t, tracker.countHostPath("example.com", "/from-pdf"))
+	require.Zero(t, tracker.countHostPath("example.com", "/from-json"))
+
+	xhtml := findPageByPath(t, report, "/page.xhtml")
+	require.Equal(t, "application/xhtml+xml", xhtml.ContentType)
+	require.Equal(t, "X", xhtml.SEO.Title)
+	require.Zero(t, xhtml.SizeBytes)
+
+	sniffed := findPageByPath(t, report, "/sniffed")
+	require.Equal(t, "text/html", sniffed.ContentType)
+	require.Equal(t, "S", sniffed.SEO.Title)
+}
+
+func TestSpec_ContentType_SkipNonHTMLBodies(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	var bodyRead atomic.Bool
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body><a href="/video.mp4"></a></body></html>`
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+		routeID("https", "example.com", "/video.mp4"): func(req *http.Request) (*http.Response, error) {
+			header := http.Header{"Content-Type": []string{"video/mp4"}, "Content-Length": []string{"1000"}}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       io.NopCloser(&readTracker{Reader: bytes.NewReader([]byte(strings.Repeat("v", 1000))), read: &bodyRead}),
+				Request:    req,
+			}, nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 2, 0, client, clock)
+	opts.SkipNonHTMLBodies = true
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	video := findPageByPath(t, report, "/video.mp4")
+	require.Equal(t, "video/mp4", video.ContentType)
+	require.Equal(t, int64(1000), video.SizeBytes)
+	require.False(t, bodyRead.Load(), "non-HTML body must not be read")
+}
+
+type readTracker struct {
+	io.Reader
+	read *atomic.Bool
+}
+
+func (r *readTracker) Read(p []byte) (int, error) {
+	r.read.Store(true)
+
+	return r.Reader.Read(p)
+}
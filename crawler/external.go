@@ -0,0 +1,78 @@
+package crawler
+
+import (
+	"context"
+
+	"code/internal/fetcher"
+)
+
+// externalCheckEntry holds the result of checking one link to another site.
+type externalCheckEntry struct {
+	link   BrokenLink
+	broken bool
+	ready  chan struct{}
+}
+
+// externalCheckPoolSize returns Options.ExternalConcurrency, or the size of the pool for
+// links on the site when it is not set.
+func externalCheckPoolSize(opts Options) int {
+	if opts.ExternalConcurrency > 0 {
+		return opts.ExternalConcurrency
+	}
+
+	return linkCheckPoolSize(opts)
+}
+
+// newExternalFetcher returns pageFetch with Options.ExternalTimeout, if set, as its timeout.
+func newExternalFetcher(opts Options, pageFetch *fetcher.Fetcher) *fetcher.Fetcher {
+	if opts.ExternalTimeout <= 0 {
+		return pageFetch
+	}
+
+	return pageFetch.WithTimeout(opts.ExternalTimeout)
+}
+
+// linkCheckerFor returns the pool that checks a link to rawURL, or nil when the link check
+// patterns exclude it or the link check mode does not fetch it. Such links are treated as
+// working, so links on the site are still crawled.
+func (a *analyzer) linkCheckerFor(rawURL string) *linkChecker {
+	if !a.site.checks(rawURL) || !a.site.fetchesLink(rawURL) {
+		return nil
+	}
+
+	if a.site.sameSite(rawURL) {
+		return a.linkCheck
+	}
+
+	return a.externalCheck
+}
+
+// checkExternalLink checks a link to another site once per crawl: every later link with the
+// same normalized URL, from any page, reuses the result. External checks are bounded by
+// their own pool rather than by MaxConcurrentFetch, and their bodies are not cached. Links to
+// other sites are never crawled, so crawled is always false.
+func (a *analyzer) checkExternalLink(ctx context.Context, absoluteURL string, _ bool) (BrokenLink, bool) {
+	key := a.normalizer.Key(absoluteURL)
+
+	a.externalMu.Lock()
+
+	if cached, ok := a.externalChecks[key]; ok {
+		a.externalMu.Unlock()
+
+		select {
+		case <-cached.ready:
+			return cached.link, cached.broken
+		case <-ctx.Done():
+			return BrokenLink{URL: absoluteURL, Error: errorString(ctx.Err(), 0)}, true
+		}
+	}
+
+	entry := &externalCheckEntry{ready: make(chan struct{})}
+	a.externalChecks[key] = entry
+	a.externalMu.Unlock()
+
+	entry.link, entry.broken = a.brokenLinkResult(ctx, absoluteURL, a.fetchExternalLink)
+	close(entry.ready)
+
+	return entry.link, entry.broken
+}
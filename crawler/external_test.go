@@ -0,0 +1,146 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func linkCheckFixtureClient(t *testing.T) (*http.Client, *callTracker) {
+	t.Helper()
+
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	notFound := func(req *http.Request) (*http.Response, error) {
+		return responseForRequest(req, http.StatusNotFound, "missing", nil), nil
+	}
+
+	return newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body>
+				<a href="/a">a</a>
+				<a href="/missing">missing</a>
+				<a href="https://other.test/dead">dead</a>
+				<a href="https://other.test/ok">ok</a>
+			</body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "example.com", "/a"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body>
+				<a href="https://other.test/dead">dead again</a>
+				<a href="https://OTHER.test/ok">ok again</a>
+			</body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "example.com", "/missing"): notFound,
+		routeID("https", "other.test", "/dead"):     notFound,
+		routeID("https", "other.test", "/ok"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "ok", nil), nil
+		},
+	})
+}
+
+func brokenLinkURLs(page *Page) []string {
+	urls := make([]string, 0, len(page.BrokenLinks))
+	for _, link := range page.BrokenLinks {
+		urls = append(urls, link.URL)
+	}
+
+	return urls
+}
+
+func TestSpec_LinkCheck_ExternalLinksCheckedOncePerCrawl(t *testing.T) {
+	t.Parallel()
+
+	client, tracker := linkCheckFixtureClient(t)
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, &testClock{now: fixtureTime})
+	opts.ExternalConcurrency = 4
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, tracker.countHostPath("other.test", "/dead"))
+	require.Equal(t, 1, tracker.countHostPath("other.test", "/ok"))
+
+	require.ElementsMatch(t, []string{
+		"https://example.com/missing",
+		"https://other.test/dead",
+	}, brokenLinkURLs(findPageByPath(t, report, "/")))
+	require.Equal(t, []string{"https://other.test/dead"}, brokenLinkURLs(findPageByPath(t, report, "/a")))
+}
+
+func TestSpec_LinkCheck_InternalOnlySkipsOtherSites(t *testing.T) {
+	t.Parallel()
+
+	client, tracker := linkCheckFixtureClient(t)
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, &testClock{now: fixtureTime})
+	opts.LinkCheck = "internal"
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.Zero(t, tracker.countHostPath("other.test", "/dead"))
+	require.Zero(t, tracker.countHostPath("other.test", "/ok"))
+	require.Equal(t, []string{"https://example.com/missing"}, brokenLinkURLs(findPageByPath(t, report, "/")))
+	require.Empty(t, findPageByPath(t, report, "/a").BrokenLinks)
+}
+
+func TestSpec_LinkCheck_NoneStillCrawlsTheSite(t *testing.T) {
+	t.Parallel()
+
+	client, tracker := linkCheckFixtureClient(t)
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, &testClock{now: fixtureTime})
+	opts.LinkCheck = "none"
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.Zero(t, tracker.countHostPath("other.test", "/dead"))
+	require.ElementsMatch(t, []string{
+		"https://example.com",
+		"https://example.com/a",
+		"https://example.com/missing",
+	}, pageURLs(report))
+	require.Empty(t, findPageByPath(t, report, "/").BrokenLinks)
+	require.Equal(t, statusError, findPageByPath(t, report, "/missing").Status)
+}
+
+func TestSpec_LinkCheck_ExternalTimeout(t *testing.T) {
+	t.Parallel()
+
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	deadlines := make(chan time.Duration, 1)
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body><a href="https://other.test/slow">slow</a></body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "other.test", "/slow"): func(req *http.Request) (*http.Response, error) {
+			deadline, _ := req.Context().Deadline()
+			deadlines <- time.Until(deadline)
+			return responseForRequest(req, http.StatusOK, "ok", nil), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, &testClock{now: fixtureTime})
+	opts.Timeout = time.Hour
+	opts.ExternalTimeout = time.Minute
+
+	_, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+	require.LessOrEqual(t, <-deadlines, time.Minute)
+}
+
+func TestSpec_LinkCheck_InvalidModeFails(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{})
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, &testClock{now: fixtureTime})
+	opts.LinkCheck = "external"
+
+	_, err := analyzeReport(context.Background(), opts)
+	require.ErrorContains(t, err, "invalid link check mode")
+}
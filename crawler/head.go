@@ -0,0 +1,64 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"code/internal/breaker"
+	"code/internal/fetcher"
+)
+
+// fetchExternalLink fetches a link to another site for the broken link check. With HeadChecks
+// it tries HEAD first and falls back to GET when the HEAD result cannot be trusted.
+func (a *analyzer) fetchExternalLink(ctx context.Context, absoluteURL string) (fetcher.Result, error) {
+	if a.options.HeadChecks {
+		result, err := a.externalFetch.Head(ctx, absoluteURL)
+		if !needsGetFallback(result, err) {
+			return result, err
+		}
+	}
+
+	return a.externalFetch.Fetch(ctx, absoluteURL)
+}
+
+// headWithCache checks a link on the site with HEAD and falls back to GET through the fetch
+// cache when the HEAD result cannot be trusted. HEAD results are cached apart from pages.
+func (a *analyzer) headWithCache(ctx context.Context, absoluteURL string) (fetcher.Result, error) {
+	result, err := a.cachedFetch(ctx, http.MethodHead+" "+absoluteURL, func(ctx context.Context) (fetcher.Result, error) {
+		return a.fetch.Head(ctx, absoluteURL)
+	})
+	if !needsGetFallback(result, err) {
+		return result, err
+	}
+
+	return a.fetchWithCache(ctx, absoluteURL)
+}
+
+// fetchAsset fetches an asset, trying HEAD first when head is set. The HEAD result is used
+// only if it is trustworthy and carries Content-Length for the asset size.
+func fetchAsset(ctx context.Context, fetch *fetcher.Fetcher, absoluteURL string, head bool) (fetcher.Result, error) {
+	if head {
+		result, err := fetch.Head(ctx, absoluteURL)
+		if !needsGetFallback(result, err) &&
+			(result.StatusCode >= http.StatusBadRequest || result.Header.Get("Content-Length") != "") {
+			return result, err
+		}
+	}
+
+	return fetch.Fetch(ctx, absoluteURL)
+}
+
+// needsGetFallback reports whether a HEAD result cannot be trusted and GET must be used instead.
+// Servers without HEAD support answer 405 or 501, others fail the request or return an error
+// GET would not; only 404, 410 and 429 are taken at face value.
+func needsGetFallback(result fetcher.Result, err error) bool {
+	switch result.StatusCode {
+	case 0:
+		return err != nil && !errors.Is(err, breaker.ErrOpen)
+	case http.StatusNotFound, http.StatusGone, http.StatusTooManyRequests:
+		return false
+	default:
+		return result.StatusCode >= http.StatusBadRequest
+	}
+}
@@ -0,0 +1,164 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type methodCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *methodCounter) add(req *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.counts == nil {
+		m.counts = map[string]int{}
+	}
+	m.counts[req.Method+" "+req.URL.Path]++
+}
+
+func (m *methodCounter) count(key string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.counts[key]
+}
+
+func TestSpec_HeadChecks_FallBackToGet(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	methods := &methodCounter{}
+	headers := func(length string) http.Header {
+		return http.Header{"Content-Length": []string{length}}
+	}
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body>
+				<img src="/big.png"><img src="/no-head.png"><img src="/no-length.png">
+				<a href="https://other.test/ok"></a><a href="https://other.test/gone"></a>
+				<a href="https://other.test/no-head"></a>
+			</body></html>`
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+		routeID("https", "example.com", "/big.png"): func(req *http.Request) (*http.Response, error) {
+			methods.add(req)
+			return responseForRequest(req, http.StatusOK, "", headers("1000000")), nil
+		},
+		routeID("https", "example.com", "/no-head.png"): func(req *http.Request) (*http.Response, error) {
+			methods.add(req)
+			if req.Method == http.MethodHead {
+				return responseForRequest(req, http.StatusMethodNotAllowed, "", nil), nil
+			}
+			return responseForRequest(req, http.StatusOK, "png", headers("3")), nil
+		},
+		routeID("https", "example.com", "/no-length.png"): func(req *http.Request) (*http.Response, error) {
+			methods.add(req)
+			return responseForRequest(req, http.StatusOK, "pngpng", nil), nil
+		},
+		routeID("https", "other.test", "/ok"): func(req *http.Request) (*http.Response, error) {
+			methods.add(req)
+			return responseForRequest(req, http.StatusOK, "ok", nil), nil
+		},
+		routeID("https", "other.test", "/gone"): func(req *http.Request) (*http.Response, error) {
+			methods.add(req)
+			return responseForRequest(req, http.StatusGone, "", nil), nil
+		},
+		routeID("https", "other.test", "/no-head"): func(req *http.Request) (*http.Response, error) {
+			methods.add(req)
+			if req.Method == http.MethodHead {
+				return responseForRequest(req, http.StatusNotImplemented, "", nil), nil
+			}
+			return responseForRequest(req, http.StatusOK, "ok", nil), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, clock)
+	opts.HeadChecks = true
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	wantCounts := map[string]int{
+		"HEAD /big.png":       1,
+		"GET /big.png":        0,
+		"HEAD /no-head.png":   1,
+		"GET /no-head.png":    1,
+		"HEAD /no-length.png": 1,
+		"GET /no-length.png":  1,
+		"HEAD /ok":            1,
+		"GET /ok":             0,
+		"HEAD /gone":          1,
+		"GET /gone":           0,
+		"HEAD /no-head":       1,
+		"GET /no-head":        1,
+	}
+	for key, want := range wantCounts {
+		require.Equal(t, want, methods.count(key), key)
+	}
+
+	root := findPageByPath(t, report, "/")
+	require.Len(t, root.BrokenLinks, 1)
+	require.Equal(t, "https://other.test/gone", root.BrokenLinks[0].URL)
+	require.Equal(t, http.StatusGone, root.BrokenLinks[0].StatusCode)
+
+	sizes := map[string]int64{}
+	for _, asset := range root.Assets {
+		require.Empty(t, asset.Error, asset.URL)
+		sizes[asset.URL] = asset.SizeBytes
+	}
+	require.Equal(t, map[string]int64{
+		fixtureBaseURL + "/big.png":       1000000,
+		fixtureBaseURL + "/no-head.png":   3,
+		fixtureBaseURL + "/no-length.png": 6,
+	}, sizes)
+}
+
+func TestSpec_HeadChecks_OnlyCrawledSiteLinksAreDownloaded(t *testing.T) {
+	t.Parallel()
+
+	methods := &methodCounter{}
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	page := func(body string) roundTripResponder {
+		return func(req *http.Request) (*http.Response, error) {
+			methods.add(req)
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		}
+	}
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): page(`<html><body>
+			<a href="/section">section</a>
+			<a href="/private" rel="nofollow">private</a>
+		</body></html>`),
+		routeID("https", "example.com", "/section"): page(`<html><body><a href="/leaf">leaf</a></body></html>`),
+		routeID("https", "example.com", "/private"): page(`<html><body>private</body></html>`),
+		routeID("https", "example.com", "/leaf"):    page(`<html><body>leaf</body></html>`),
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 2, 0, client, &testClock{now: fixtureTime})
+	opts.HeadChecks = true
+	opts.RespectNofollow = true
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"https://example.com", "https://example.com/section"}, pageURLs(report))
+
+	wantCounts := map[string]int{
+		"GET /section":  1,
+		"HEAD /section": 0,
+		"HEAD /private": 1,
+		"GET /private":  0,
+		"HEAD /leaf":    1,
+		"GET /leaf":     0,
+	}
+	for key, want := range wantCounts {
+		require.Equal(t, want, methods.count(key), key)
+	}
+}
@@ -0,0 +1,72 @@
+package crawler
+
+import (
+	"net/http"
+	"net/url"
+
+	"code/internal/robots"
+)
+
+// applyRobotsHeader flags a page by its X-Robots-Tag headers when RespectNofollow is set.
+func (a *analyzer) applyRobotsHeader(page *Page, header http.Header) {
+	if !a.options.RespectNofollow {
+		return
+	}
+
+	directives := robots.Directives{}
+	for _, value := range header.Values("X-Robots-Tag") {
+		directives = directives.Merge(robots.ParseDirectives(value))
+	}
+
+	page.NoIndex = directives.NoIndex
+	page.NoFollow = directives.NoFollow
+}
+
+// applyRobotsMeta adds the directives of the page's <meta name="robots"> tags.
+func (a *analyzer) applyRobotsMeta(page *Page, meta string) {
+	if !a.options.RespectNofollow {
+		return
+	}
+
+	directives := robots.ParseDirectives(meta).Merge(robots.Directives{
+		NoIndex:  page.NoIndex,
+		NoFollow: page.NoFollow,
+	})
+
+	page.NoIndex = directives.NoIndex
+	page.NoFollow = directives.NoFollow
+}
+
+// followedLinks returns the checked links that may be crawled. With RespectNofollow, a
+// nofollow page yields none, and a link is kept only if the page has it at least once
+// without rel="nofollow".
+func (a *analyzer) followedLinks(page Page, followHrefs []string, links []string) []string {
+	if !a.options.RespectNofollow {
+		return links
+	}
+
+	followed := []string{}
+	if page.NoFollow {
+		return followed
+	}
+
+	base, err := url.Parse(pageBaseURL(page))
+	if err != nil {
+		return followed
+	}
+
+	keys := make(map[string]bool, len(followHrefs))
+	for _, href := range followHrefs {
+		if absoluteURL, ok := a.normalizer.Resolve(base, href); ok {
+			keys[a.normalizer.Key(absoluteURL)] = true
+		}
+	}
+
+	for _, link := range links {
+		if keys[a.normalizer.Key(link)] {
+			followed = append(followed, link)
+		}
+	}
+
+	return followed
+}
@@ -0,0 +1,91 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func nofollowFixtureClient(t *testing.T) (*http.Client, *callTracker) {
+	t.Helper()
+
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	page := func(req *http.Request) (*http.Response, error) {
+		return responseForRequest(req, http.StatusOK, `<html></html>`, html), nil
+	}
+
+	return newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body>
+				<a href="/open">open</a>
+				<a href="/ad" rel="nofollow">ad</a>
+				<a href="/twice" rel="nofollow">one</a><a href="/twice">two</a>
+				<a href="/hidden">hidden</a>
+				<a href="/private">private</a>
+			</body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "example.com", "/open"):  page,
+		routeID("https", "example.com", "/ad"):    page,
+		routeID("https", "example.com", "/twice"): page,
+		routeID("https", "example.com", "/hidden"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><head><meta name="robots" content="noindex, nofollow"></head>
+				<body><a href="/behind-hidden">x</a></body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "example.com", "/private"): func(req *http.Request) (*http.Response, error) {
+			header := http.Header{"Content-Type": []string{"text/html"}, "X-Robots-Tag": []string{"noindex"}}
+			return responseForRequest(req, http.StatusOK, `<html></html>`, header), nil
+		},
+		routeID("https", "example.com", "/behind-hidden"): page,
+	})
+}
+
+func TestSpec_Nofollow_SkipsNofollowLinksAndFlagsNoindex(t *testing.T) {
+	t.Parallel()
+
+	client, tracker := nofollowFixtureClient(t)
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, &testClock{now: fixtureTime})
+	opts.RespectNofollow = true
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.ElementsMatch(t, []string{
+		"https://example.com",
+		"https://example.com/open",
+		"https://example.com/twice",
+		"https://example.com/hidden",
+		"https://example.com/private",
+	}, pageURLs(report))
+
+	// Nofollow links are still checked once, but never crawled.
+	require.Equal(t, 1, tracker.countHostPath("example.com", "/ad"))
+	require.Equal(t, 1, tracker.countHostPath("example.com", "/behind-hidden"))
+
+	hidden := findPageByPath(t, report, "/hidden")
+	require.True(t, hidden.NoIndex)
+	require.True(t, hidden.NoFollow)
+
+	private := findPageByPath(t, report, "/private")
+	require.True(t, private.NoIndex)
+	require.False(t, private.NoFollow)
+
+	require.False(t, findPageByPath(t, report, "/open").NoIndex)
+}
+
+func TestSpec_Nofollow_IgnoredByDefault(t *testing.T) {
+	t.Parallel()
+
+	client, _ := nofollowFixtureClient(t)
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, &testClock{now: fixtureTime})
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.NotNil(t, findPageByPath(t, report, "/ad"))
+	require.NotNil(t, findPageByPath(t, report, "/behind-hidden"))
+	require.False(t, findPageByPath(t, report, "/hidden").NoIndex)
+}
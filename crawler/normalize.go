@@ -0,0 +1,39 @@
+package crawler
+
+import (
+	"fmt"
+	"slices"
+
+	"code/internal/urlutil"
+)
+
+// newNormalizer builds the URL normalizer shared by every part of the crawl: root URL,
+// links, assets, sitemap URLs, page dedup and broken link dedup.
+func newNormalizer(opts Options) (urlutil.Normalizer, error) {
+	trailingSlash, err := urlutil.ParseTrailingSlash(opts.TrailingSlash)
+	if err != nil {
+		return urlutil.Normalizer{}, fmt.Errorf("invalid url normalization: %w", err)
+	}
+
+	stripParams := slices.Clone(opts.StripParams)
+	if opts.StripTrackingParams {
+		stripParams = append(stripParams, urlutil.TrackingParams...)
+	}
+
+	return urlutil.Normalizer{
+		KeepCase:        opts.KeepURLCase,
+		KeepDefaultPort: opts.KeepDefaultPorts,
+		StripParams:     stripParams,
+		SortQuery:       opts.SortQuery,
+		TrailingSlash:   trailingSlash,
+	}, nil
+}
+
+// brokenLinkNormalizer returns the normalizer broken links are reported and deduplicated
+// with. It strips the trailing slash in every mode, so /missing and /missing/ are always
+// one broken link.
+func brokenLinkNormalizer(normalizer urlutil.Normalizer) urlutil.Normalizer {
+	normalizer.TrailingSlash = urlutil.TrailingSlashStrip
+
+	return normalizer
+}
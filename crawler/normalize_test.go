@@ -0,0 +1,68 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_Normalize_StripsTrackingParamsAndSortsQuery(t *testing.T) {
+	t.Parallel()
+
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	page := func(req *http.Request) (*http.Response, error) {
+		return responseForRequest(req, http.StatusOK, `<html></html>`, html), nil
+	}
+	client, tracker := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body>
+				<a href="/post?utm_source=news&utm_medium=email">1</a>
+				<a href="/post?fbclid=abc">2</a>
+				<a href="HTTPS://Example.com:443/post#comments">3</a>
+				<a href="/search?q=go&page=2&ref=nav">4</a>
+				<a href="/search?page=2&q=go">5</a>
+				<a href="/missing/?gclid=1">6</a>
+				<a href="/missing">7</a>
+			</body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "example.com", "/post"):   page,
+		routeID("https", "example.com", "/search"): page,
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 2, 0, client, &testClock{now: fixtureTime})
+	opts.StripTrackingParams = true
+	opts.StripParams = []string{"ref"}
+	opts.SortQuery = true
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.ElementsMatch(t, []string{
+		"https://example.com",
+		"https://example.com/post",
+		"https://example.com/search?page=2&q=go",
+	}, pageURLs(report))
+	require.Equal(t, 1, tracker.countHostPath("example.com", "/post"))
+	require.Equal(t, 1, tracker.countHostPath("example.com", "/search"))
+
+	// Broken links drop tracking parameters like pages, and their trailing slash as before.
+	root := findPageByPath(t, report, "/")
+	require.Len(t, root.BrokenLinks, 1)
+	require.Equal(t, "https://example.com/missing", root.BrokenLinks[0].URL)
+}
+
+func TestSpec_Normalize_InvalidTrailingSlashFails(t *testing.T) {
+	t.Parallel()
+
+	client, tracker := newTrackedClient(t, map[string]roundTripResponder{})
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, &testClock{now: fixtureTime})
+	opts.TrailingSlash = "sometimes"
+
+	_, err := analyzeReport(context.Background(), opts)
+	require.ErrorContains(t, err, "invalid url normalization")
+	require.Zero(t, tracker.countHostPath("example.com", "/"))
+}
@@ -0,0 +1,79 @@
+package crawler
+
+import "sort"
+
+// findOrphans compares sitemap URLs with the pages reached from the root through links.
+// Only links found on crawled pages count, so the result is bounded by Depth.
+func (a *analyzer) findOrphans(state *crawlState) *Orphans {
+	rootURL := a.baseURL.String()
+	linked := linkedFrom(a.normalizer.Key(rootURL), state.links)
+	listed := make(map[string]bool, len(a.sitemap))
+	orphans := &Orphans{
+		SitemapOnly:  []string{},
+		NotInSitemap: []string{},
+	}
+
+	for _, pageURL := range a.sitemap {
+		key := a.normalizer.Key(pageURL)
+		if listed[key] {
+			continue
+		}
+
+		listed[key] = true
+
+		if key != a.normalizer.Key(rootURL) && !linked[key] {
+			orphans.SitemapOnly = append(orphans.SitemapOnly, pageURL)
+		}
+	}
+
+	for _, page := range a.report.Pages {
+		if page.Status == statusOK && !a.listedPage(listed, page) {
+			orphans.NotInSitemap = append(orphans.NotInSitemap, page.URL)
+		}
+	}
+
+	sort.Strings(orphans.SitemapOnly)
+	sort.Strings(orphans.NotInSitemap)
+
+	return orphans
+}
+
+// linkedFrom returns the keys of the pages the link graph reaches from the root key.
+// Links between pages that the root never reaches, such as sitemap-only pages linking to
+// each other, are not followed.
+func linkedFrom(root string, links map[string][]string) map[string]bool {
+	linked := map[string]bool{}
+	queue := []string{root}
+	visited := map[string]bool{root: true}
+
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+
+		for _, link := range links[key] {
+			linked[link] = true
+
+			if !visited[link] {
+				visited[link] = true
+				queue = append(queue, link)
+			}
+		}
+	}
+
+	return linked
+}
+
+// listedPage reports whether the sitemap lists a page under its URL or one of its aliases.
+func (a *analyzer) listedPage(listed map[string]bool, page Page) bool {
+	if listed[a.normalizer.Key(page.URL)] {
+		return true
+	}
+
+	for _, alias := range page.Aliases {
+		if listed[a.normalizer.Key(alias)] {
+			return true
+		}
+	}
+
+	return false
+}
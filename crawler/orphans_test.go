@@ -0,0 +1,87 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_Orphans_ComparesSitemapWithLinkGraph(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	okPage := func(body string) roundTripResponder {
+		return func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		}
+	}
+	routes := map[string]roundTripResponder{
+		routeID("https", "example.com", "/sitemap.xml"): func(req *http.Request) (*http.Response, error) {
+			body := `<urlset>
+				<url><loc>https://example.com/</loc></url>
+				<url><loc>https://example.com/listed</loc></url>
+				<url><loc>https://example.com/orphan</loc></url>
+			</urlset>`
+			return responseForRequest(req, http.StatusOK, body, nil), nil
+		},
+		routeID("https", "example.com", "/"):         okPage(`<html><body><a href="/listed">l</a><a href="/unlisted">u</a></body></html>`),
+		routeID("https", "example.com", "/listed"):   okPage(`<html><body>listed</body></html>`),
+		routeID("https", "example.com", "/orphan"):   okPage(`<html><body><a href="/">home</a></body></html>`),
+		routeID("https", "example.com", "/unlisted"): okPage(`<html><body>unlisted</body></html>`),
+	}
+	client, _ := newTrackedClient(t, routes)
+
+	opts := optionsForContract(fixtureBaseURL, 2, 0, client, clock)
+	opts.UseSitemap = true
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+	require.NotNil(t, report.Orphans)
+	require.Equal(t, []string{"https://example.com/orphan"}, report.Orphans.SitemapOnly)
+	require.Equal(t, []string{"https://example.com/unlisted"}, report.Orphans.NotInSitemap)
+}
+
+func TestSpec_Orphans_SitemapPagesLinkingToEachOtherStayOrphans(t *testing.T) {
+	t.Parallel()
+
+	okPage := func(body string) roundTripResponder {
+		return func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		}
+	}
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/sitemap.xml"): func(req *http.Request) (*http.Response, error) {
+			body := `<urlset>
+				<url><loc>https://example.com/listed</loc></url>
+				<url><loc>https://example.com/island-a</loc></url>
+				<url><loc>https://example.com/island-b</loc></url>
+			</urlset>`
+			return responseForRequest(req, http.StatusOK, body, nil), nil
+		},
+		routeID("https", "example.com", "/"):         okPage(`<html><body><a href="/listed">l</a></body></html>`),
+		routeID("https", "example.com", "/listed"):   okPage(`<html><body>listed</body></html>`),
+		routeID("https", "example.com", "/island-a"): okPage(`<html><body><a href="/island-b">b</a></body></html>`),
+		routeID("https", "example.com", "/island-b"): okPage(`<html><body><a href="/island-a">a</a></body></html>`),
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, &testClock{now: fixtureTime})
+	opts.UseSitemap = true
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+	require.NotNil(t, report.Orphans)
+	require.Equal(t, []string{"https://example.com/island-a", "https://example.com/island-b"}, report.Orphans.SitemapOnly)
+}
+
+func TestSpec_Orphans_AbsentWithoutSitemapMode(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{})
+
+	report, err := analyzeReport(context.Background(), optionsForContract(fixtureBaseURL, 1, 0, client, clock))
+	require.Error(t, err)
+	require.Nil(t, report.Orphans)
+}
@@ -0,0 +1,68 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_RateLimit_AdaptiveBacksOffAndReportsRate(t *testing.T) {
+	t.Parallel()
+
+	clock := &rateClock{now: fixtureTime}
+	var throttled atomic.Int32
+	routes := map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body><a href="/a"></a></body></html>`
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+		routeID("https", "example.com", "/a"): func(req *http.Request) (*http.Response, error) {
+			if throttled.Add(1) <= 2 {
+				return responseForRequest(req, http.StatusTooManyRequests, "slow down", nil), nil
+			}
+
+			return responseForRequest(req, http.StatusOK, "ok", nil), nil
+		},
+	}
+	client, _ := newTrackedClient(t, routes)
+
+	opts := Options{
+		URL:          fixtureBaseURL,
+		Depth:        1,
+		Concurrency:  1,
+		Retries:      2,
+		Timeout:      time.Second,
+		UserAgent:    "test-agent",
+		AdaptiveRate: true,
+		HTTPClient:   client,
+		Clock:        clock,
+	}
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+	require.Equal(t, []HostRate{{
+		Host:            "example.com",
+		FinalIntervalMs: 100,
+		MaxIntervalMs:   200,
+		Backoffs:        2,
+	}}, report.RateControl)
+}
+
+func TestSpec_RateLimit_RateControlAbsentByDefault(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	client := newFixtureClientWithRoutes(t, map[string]roundTripResponder{
+		"/": func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "<html></html>", nil), nil
+		},
+	})
+
+	report, err := analyzeReport(context.Background(), optionsForContract(fixtureBaseURL, 1, 0, client, clock))
+	require.NoError(t, err)
+	require.Nil(t, report.RateControl)
+}
@@ -58,6 +58,48 @@ func TestSpec_RateLimit_RPSOverridesDelay_AndIsGlobal(t *testing.T) {
 	}
 }
 
+func TestSpec_RateLimit_BurstAllowsInitialRequestsWithoutWaiting(t *testing.T) {
+	t.Parallel()
+
+	clock := &rateClock{now: fixtureTime}
+	client := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "" || req.URL.Path == "/" {
+				body := `<html><body>
+					<a href="/a"></a><a href="/b"></a><a href="/c"></a>
+					<a href="/d"></a><a href="/e"></a>
+				</body></html>`
+				return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+			}
+
+			return responseForRequest(req, http.StatusOK, "<html><body>ok</body></html>", nil), nil
+		}),
+	}
+
+	opts := Options{
+		URL:         fixtureBaseURL,
+		Depth:       1,
+		Concurrency: 1,
+		RPS:         5,
+		Burst:       3,
+		Retries:     0,
+		Timeout:     time.Second,
+		UserAgent:   "test-agent",
+		HTTPClient:  client,
+		Clock:       clock,
+	}
+
+	_, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	// Six requests: three use the burst, the other three wait for one refill each.
+	require.Equal(t, []time.Duration{
+		200 * time.Millisecond,
+		200 * time.Millisecond,
+		200 * time.Millisecond,
+	}, clock.sleepDurations())
+}
+
 func TestSpec_RateLimit_CancelStopsWaiting(t *testing.T) {
 	t.Parallel()
 
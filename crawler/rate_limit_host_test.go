@@ -0,0 +1,69 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_RateLimit_PerHostOverrideDoesNotSlowOtherHosts(t *testing.T) {
+	t.Parallel()
+
+	clock := &rateClock{now: fixtureTime}
+	routes := map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body>
+				<a href="/a"></a><a href="/b"></a>
+				<a href="https://other.test/x"></a><a href="https://other.test/y"></a>
+			</body></html>`
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+		routeID("https", "example.com", "/a"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "ok", nil), nil
+		},
+		routeID("https", "example.com", "/b"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "ok", nil), nil
+		},
+		routeID("https", "other.test", "/x"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "ok", nil), nil
+		},
+		routeID("https", "other.test", "/y"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "ok", nil), nil
+		},
+	}
+	client, _ := newTrackedClient(t, routes)
+
+	opts := Options{
+		URL:         fixtureBaseURL,
+		Depth:       1,
+		Concurrency: 1,
+		HostRPS:     map[string]float64{"example.com": 5},
+		Timeout:     time.Second,
+		UserAgent:   "test-agent",
+		HTTPClient:  client,
+		Clock:       clock,
+	}
+
+	_, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	// Three example.com requests need two 200ms gaps; other.test is unlimited.
+	require.Equal(t, []time.Duration{200 * time.Millisecond, 200 * time.Millisecond}, clock.sleepDurations())
+}
+
+func TestNewLimiterRegistryIntervals(t *testing.T) {
+	t.Parallel()
+
+	registry := newLimiterRegistry(Options{
+		PerHostRPS: 2,
+		HostRPS:    map[string]float64{"fast.test": 10, "free.test": 0},
+		Clock:      &testClock{now: fixtureTime},
+	})
+
+	require.Equal(t, 500*time.Millisecond, registry.Interval("example.com"))
+	require.Equal(t, 100*time.Millisecond, registry.Interval("fast.test"))
+	require.Zero(t, registry.Interval("free.test"))
+}
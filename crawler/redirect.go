@@ -0,0 +1,159 @@
+package crawler
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+
+	"code/internal/fetcher"
+	"code/internal/urlutil"
+)
+
+const defaultMaxRedirectHops = 5
+
+// redirectPolicy turns the redirects recorded by the fetcher into report entries.
+type redirectPolicy struct {
+	site    siteScope
+	maxHops int
+}
+
+func newRedirectPolicy(opts Options, site siteScope) redirectPolicy {
+	maxHops := opts.MaxRedirectHops
+	if maxHops <= 0 {
+		maxHops = defaultMaxRedirectHops
+	}
+
+	return redirectPolicy{site: site, maxHops: maxHops}
+}
+
+// describe returns the redirect chain followed for rawURL, or nil if it did not redirect.
+func (p redirectPolicy) describe(rawURL string, result fetcher.Result, err error) *Redirect {
+	if len(result.Redirects) == 0 {
+		return nil
+	}
+
+	hops := make([]RedirectHop, 0, len(result.Redirects))
+	permanent := false
+
+	for _, hop := range result.Redirects {
+		hops = append(hops, RedirectHop{URL: hop.URL, StatusCode: hop.StatusCode})
+		permanent = permanent || isPermanentRedirect(hop.StatusCode)
+	}
+
+	return &Redirect{
+		Hops:      hops,
+		FinalURL:  result.FinalURL,
+		Loop:      errors.Is(err, fetcher.ErrRedirectLoop),
+		TooLong:   len(hops) > p.maxHops || errors.Is(err, fetcher.ErrTooManyRedirects),
+		External:  p.site.sameSite(rawURL) && !p.site.sameSite(result.FinalURL),
+		Permanent: permanent && p.site.sameSite(rawURL),
+	}
+}
+
+// pageBaseURL returns the URL relative links on a page resolve against: the final URL
+// after redirects, or the page URL itself.
+func pageBaseURL(page Page) string {
+	if page.Redirect != nil && page.Redirect.FinalURL != "" {
+		return page.Redirect.FinalURL
+	}
+
+	return page.URL
+}
+
+// redirectedOffSite reports whether a page URL redirected outside the crawled site.
+func redirectedOffSite(page Page) bool {
+	return page.Redirect != nil && page.Redirect.External
+}
+
+// finalPageKey identifies the document a page ended at, or returns "" for pages that
+// got no response and therefore are never merged.
+func finalPageKey(page Page, normalizer urlutil.Normalizer) string {
+	if page.HTTPStatus == 0 {
+		return ""
+	}
+
+	return normalizer.Key(pageBaseURL(page))
+}
+
+// pageClaims records the documents the crawl fetches, by normalized key. A worker claims a
+// page URL before fetching it and the final URL as soon as its redirects resolve, so a
+// document reached through several URLs is parsed and link-checked once. It is safe for
+// concurrent use.
+type pageClaims struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func newPageClaims() *pageClaims {
+	return &pageClaims{keys: map[string]bool{}}
+}
+
+// claim takes key and reports whether it was still free.
+func (c *pageClaims) claim(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.keys[key] {
+		return false
+	}
+
+	c.keys[key] = true
+
+	return true
+}
+
+func (c *pageClaims) taken(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.keys[key]
+}
+
+// addAlias lists a page that was not crawled because another job claimed its document as an
+// alias of the page with key. Until that page is committed, the alias waits.
+func (a *aggregator) addAlias(key string, page Page) {
+	if idx, ok := a.finalPages[key]; ok {
+		primary := &a.report.Pages[idx]
+		primary.Aliases = append(primary.Aliases, page.URL)
+
+		return
+	}
+
+	a.aliases[key] = append(a.aliases[key], page)
+}
+
+// mergePage merges a page that ended at the same final URL as primary into it. The page's
+// URL becomes an alias, and its broken links and their referrers move to primary.
+func (a *aggregator) mergePage(primary *Page, page Page) {
+	primary.Aliases = append(primary.Aliases, page.URL)
+
+	if len(page.BrokenLinks) == 0 {
+		return
+	}
+
+	primary.BrokenLinks = dedupBrokenLinks(append(primary.BrokenLinks, page.BrokenLinks...), brokenLinkNormalizer(a.normalizer))
+	a.moveReferrers(page.URL, primary.URL)
+}
+
+// releaseAliases lists aliases whose page never reached the report as pages of their own.
+// That happens only when the claiming page ended somewhere else, such as a URL that
+// redirected the second time it was fetched.
+func (a *aggregator) releaseAliases() {
+	keys := make([]string, 0, len(a.aliases))
+	for key := range a.aliases {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		a.report.Pages = append(a.report.Pages, a.aliases[key]...)
+	}
+
+	a.aliases = map[string][]Page{}
+}
+
+func isPermanentRedirect(statusCode int) bool {
+	return statusCode == http.StatusMovedPermanently || statusCode == http.StatusPermanentRedirect
+}
@@ -0,0 +1,296 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"code/internal/fetcher"
+	"code/internal/urlutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+func redirectResponder(status int, location string) roundTripResponder {
+	return func(req *http.Request) (*http.Response, error) {
+		return responseForRequest(req, status, "", http.Header{"Location": []string{location}}), nil
+	}
+}
+
+func TestSpec_Redirects_RecordedForPagesLinksAndAssets(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body>
+				<a href="/old"></a><a href="/gone"></a><a href="/loop-a"></a>
+				<img src="/logo.png">
+			</body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "example.com", "/old"):    redirectResponder(http.StatusMovedPermanently, "/new"),
+		routeID("https", "example.com", "/gone"):   redirectResponder(http.StatusFound, "/missing"),
+		routeID("https", "example.com", "/loop-a"): redirectResponder(http.StatusFound, "/loop-b"),
+		routeID("https", "example.com", "/loop-b"): redirectResponder(http.StatusFound, "/loop-a"),
+		routeID("https", "example.com", "/new"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, `<html><head><title>New</title></head></html>`, html), nil
+		},
+		routeID("https", "example.com", "/logo.png"): redirectResponder(http.StatusFound, "https://cdn.example.net/logo.png"),
+		routeID("https", "cdn.example.net", "/logo.png"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "png", http.Header{"Content-Length": []string{"3"}}), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 2, 0, client, clock)
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	root := findPageByPath(t, report, "/")
+	require.Nil(t, root.Redirect)
+
+	require.Len(t, root.BrokenLinks, 2)
+	gone := root.BrokenLinks[0]
+	require.Equal(t, "https://example.com/gone", gone.URL)
+	require.Equal(t, http.StatusNotFound, gone.StatusCode)
+	require.Equal(t, &Redirect{
+		Hops:     []RedirectHop{{URL: "https://example.com/gone", StatusCode: http.StatusFound}},
+		FinalURL: "https://example.com/missing",
+	}, gone.Redirect)
+
+	loop := root.BrokenLinks[1]
+	require.Equal(t, "https://example.com/loop-a", loop.URL)
+	require.NotNil(t, loop.Redirect)
+	require.True(t, loop.Redirect.Loop)
+	require.Len(t, loop.Redirect.Hops, 2)
+	require.Contains(t, loop.Error, "redirect loop")
+
+	require.Len(t, root.Assets, 1)
+	require.Equal(t, http.StatusOK, root.Assets[0].StatusCode)
+	require.Equal(t, &Redirect{
+		Hops:     []RedirectHop{{URL: "https://example.com/logo.png", StatusCode: http.StatusFound}},
+		FinalURL: "https://cdn.example.net/logo.png",
+		External: true,
+	}, root.Assets[0].Redirect)
+
+	old := findPageByPath(t, report, "/old")
+	require.Equal(t, statusOK, old.Status)
+	require.Equal(t, http.StatusOK, old.HTTPStatus)
+	require.Equal(t, "New", old.SEO.Title)
+	require.Equal(t, &Redirect{
+		Hops:      []RedirectHop{{URL: "https://example.com/old", StatusCode: http.StatusMovedPermanently}},
+		FinalURL:  "https://example.com/new",
+		Permanent: true,
+	}, old.Redirect)
+}
+
+func TestSpec_Redirects_FlagsLongChains(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body><a href="https://other.example/1"></a></body></html>`
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+		routeID("https", "other.example", "/1"): redirectResponder(http.StatusMovedPermanently, "/2"),
+		routeID("https", "other.example", "/2"): redirectResponder(http.StatusFound, "/3"),
+		routeID("https", "other.example", "/3"): redirectResponder(http.StatusFound, "/missing"),
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, clock)
+	opts.MaxRedirectHops = 2
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	root := findPageByPath(t, report, "/")
+	require.Len(t, root.BrokenLinks, 1)
+
+	redirect := root.BrokenLinks[0].Redirect
+	require.NotNil(t, redirect)
+	require.Len(t, redirect.Hops, 3)
+	require.True(t, redirect.TooLong)
+	require.False(t, redirect.Permanent, "permanent redirects on other sites are not flagged")
+}
+
+func TestSpec_Redirects_OffSiteTargetIsNotCrawled(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	client, tracker := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, `<html><body><a href="/out"></a></body></html>`, html), nil
+		},
+		routeID("https", "example.com", "/out"): redirectResponder(http.StatusFound, "https://other.example/landing"),
+		routeID("https", "other.example", "/landing"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><head><title>Other</title></head><body><a href="/next"></a><img src="/pic.png"></body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, clock)
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	out := findPageByPath(t, report, "/out")
+	require.Equal(t, statusOK, out.Status)
+	require.NotNil(t, out.Redirect)
+	require.True(t, out.Redirect.External)
+	require.False(t, out.SEO.HasTitle)
+	require.Empty(t, out.BrokenLinks)
+	require.Empty(t, out.Assets)
+
+	require.Zero(t, tracker.countHostPath("other.example", "/next"))
+	require.Zero(t, tracker.countHostPath("other.example", "/pic.png"))
+}
+
+func TestSpec_Redirects_MergesPagesWithSameFinalURL(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	client, tracker := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body><a href="/docs"></a><a href="/manual"></a><a href="/docs/"></a></body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "example.com", "/docs"):   redirectResponder(http.StatusMovedPermanently, "/docs/"),
+		routeID("https", "example.com", "/manual"): redirectResponder(http.StatusFound, "/docs/"),
+		routeID("https", "example.com", "/docs/"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, `<html><body><a href="intro"></a></body></html>`, html), nil
+		},
+		routeID("https", "example.com", "/docs/intro"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, `<html></html>`, html), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, clock)
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	urls := make([]string, 0, len(report.Pages))
+	for _, page := range report.Pages {
+		urls = append(urls, page.URL)
+	}
+	require.ElementsMatch(t, []string{
+		"https://example.com",
+		"https://example.com/docs",
+		"https://example.com/docs/intro",
+	}, urls)
+
+	docs := findPageByPath(t, report, "/docs")
+	require.Equal(t, []string{"https://example.com/manual", "https://example.com/docs/"}, docs.Aliases)
+	require.Empty(t, docs.BrokenLinks, "relative links resolve against the final URL")
+	require.Zero(t, tracker.countHostPath("example.com", "/intro"))
+}
+
+func TestSpec_Redirects_FinalURLIsCrawledOnce(t *testing.T) {
+	t.Parallel()
+
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body><a href="/old-a"></a><a href="/old-b"></a><a href="/new"></a></body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "example.com", "/old-a"): redirectResponder(http.StatusMovedPermanently, "/new"),
+		routeID("https", "example.com", "/old-b"): redirectResponder(http.StatusMovedPermanently, "/new"),
+		routeID("https", "example.com", "/new"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, `<html><body><a href="/gone">gone</a></body></html>`, html), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 2, 0, client, &testClock{now: fixtureTime})
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"https://example.com", "https://example.com/old-a"}, pageURLs(report))
+
+	merged := findPageByPath(t, report, "/old-a")
+	require.Equal(t, []string{"https://example.com/old-b", "https://example.com/new"}, merged.Aliases)
+	require.Equal(t, []string{"https://example.com/gone"}, brokenLinkURLs(merged))
+
+	require.Equal(t, []LinkReferrer{
+		{Page: "https://example.com/old-a", Text: "gone", Tag: "a"},
+	}, report.BrokenLinks[0].Referrers)
+}
+
+func TestSpec_Redirects_ClaimedFinalURLIsNotParsedAgain(t *testing.T) {
+	t.Parallel()
+
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/old-a"): redirectResponder(http.StatusMovedPermanently, "/new"),
+		routeID("https", "example.com", "/old-b"): redirectResponder(http.StatusMovedPermanently, "/new"),
+		routeID("https", "example.com", "/new"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, `<html><body><a href="/next">next</a></body></html>`, html), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, &testClock{now: fixtureTime})
+	baseURL, err := parseRootURL(opts.URL, urlutil.Normalizer{})
+	require.NoError(t, err)
+
+	report := newReport(opts)
+	a := newAnalyzer(opts, baseURL, fetcher.New(client, time.Second, "", nil, nil, fetcher.RetryPolicy{}, opts.Clock), nil, &report)
+
+	first := a.processJob(context.Background(), crawlJob{url: fixtureBaseURL + "/old-a", depth: 1})
+	require.Empty(t, first.mergeInto)
+	require.Equal(t, []string{"https://example.com/next"}, first.links)
+
+	second := a.processJob(context.Background(), crawlJob{url: fixtureBaseURL + "/old-b", depth: 1})
+	require.Equal(t, "https://example.com/new", second.mergeInto)
+	require.Empty(t, second.links)
+
+	direct := a.processJob(context.Background(), crawlJob{url: fixtureBaseURL + "/new", depth: 1})
+	require.Equal(t, "https://example.com/new", direct.mergeInto)
+	require.Zero(t, direct.page.HTTPStatus, "a claimed page is not fetched")
+}
+
+func TestSpec_Redirects_MergedPagesKeepBrokenLinksAndReferrers(t *testing.T) {
+	t.Parallel()
+
+	report := Report{Pages: []Page{}}
+	agg := &aggregator{
+		report:     &report,
+		finalPages: map[string]int{},
+		aliases:    map[string][]Page{},
+		referrers:  map[string]map[string][]LinkReferrer{},
+	}
+	page := func(pageURL, broken string) Page {
+		return Page{
+			URL:         pageURL,
+			HTTPStatus:  http.StatusOK,
+			Redirect:    &Redirect{FinalURL: "https://example.com/new"},
+			BrokenLinks: []BrokenLink{{URL: broken, StatusCode: http.StatusNotFound}},
+		}
+	}
+
+	for _, result := range []pageResult{
+		{page: page("https://example.com/old-a", "https://example.com/x"), referrers: map[string][]LinkReferrer{
+			"https://example.com/x": {{Page: "https://example.com/old-a", Text: "x", Tag: "a"}},
+		}},
+		{page: page("https://example.com/old-b", "https://example.com/y"), referrers: map[string][]LinkReferrer{
+			"https://example.com/y": {{Page: "https://example.com/old-b", Text: "y", Tag: "a"}},
+		}},
+	} {
+		agg.recordReferrers(result)
+		agg.commitPage(result.page)
+	}
+
+	require.Len(t, report.Pages, 1)
+	require.Equal(t, []string{"https://example.com/old-b"}, report.Pages[0].Aliases)
+	require.Equal(t, []string{"https://example.com/x", "https://example.com/y"}, brokenLinkURLs(&report.Pages[0]))
+
+	index := buildBrokenLinkIndex(report.Pages, agg.referrers, urlutil.Normalizer{})
+	require.Len(t, index, 2)
+	require.Equal(t, []LinkReferrer{{Page: "https://example.com/old-a", Text: "y", Tag: "a"}}, index[1].Referrers)
+}
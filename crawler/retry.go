@@ -0,0 +1,114 @@
+package crawler
+
+import (
+	"fmt"
+	"time"
+
+	"code/internal/breaker"
+	"code/internal/fetcher"
+	"code/internal/limiter"
+)
+
+// RetryPolicy configures retries for one kind of request.
+// Retries is the number of retries after the first attempt.
+// StatusCodes lists retryable HTTP statuses (default: 429 and every 5xx).
+// ErrorClasses lists retryable transport errors: "dns", "connection_refused", "timeout", "tls"
+// (default: any network error); any other name fails the crawl.
+// BaseDelay (default: Options.Delay, or 100ms) grows by Multiplier (default 2) up to MaxDelay (default 2s).
+// MaxRetryAfter caps waits requested by Retry-After on 429/503 (default 30s).
+// Jitter in [0, 1] randomly shortens each backoff delay by up to that fraction.
+// Budget > 0 caps the total number of retries made with this policy during one crawl.
+type RetryPolicy struct {
+	Retries       int
+	StatusCodes   []int
+	ErrorClasses  []string
+	BaseDelay     time.Duration
+	MaxDelay      time.Duration
+	Multiplier    float64
+	MaxRetryAfter time.Duration
+	Jitter        float64
+	Budget        int
+}
+
+// pageRetryPolicy returns Options.Retry, falling back to Options.Retries.
+func pageRetryPolicy(opts Options) fetcher.RetryPolicy {
+	if opts.Retry == nil {
+		return fetcherRetryPolicy(RetryPolicy{Retries: opts.Retries}, opts)
+	}
+
+	return fetcherRetryPolicy(*opts.Retry, opts)
+}
+
+// validateRetryPolicies rejects error classes the fetcher does not know in Options.Retry
+// and Options.AssetRetry.
+func validateRetryPolicies(opts Options) error {
+	for _, policy := range []*RetryPolicy{opts.Retry, opts.AssetRetry} {
+		if policy == nil {
+			continue
+		}
+
+		if _, err := errorClasses(policy.ErrorClasses); err != nil {
+			return fmt.Errorf("invalid retry error class: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// fetcherRetryPolicy converts a policy already checked by validateRetryPolicies.
+func fetcherRetryPolicy(policy RetryPolicy, opts Options) fetcher.RetryPolicy {
+	baseDelay := policy.BaseDelay
+	if baseDelay <= 0 {
+		baseDelay = opts.Delay
+	}
+
+	classes, _ := errorClasses(policy.ErrorClasses)
+
+	return fetcher.RetryPolicy{
+		Retries:       policy.Retries,
+		StatusCodes:   policy.StatusCodes,
+		ErrorClasses:  classes,
+		BaseDelay:     baseDelay,
+		MaxDelay:      policy.MaxDelay,
+		Multiplier:    policy.Multiplier,
+		MaxRetryAfter: policy.MaxRetryAfter,
+		Jitter:        policy.Jitter,
+		Budget:        policy.Budget,
+	}
+}
+
+func errorClasses(names []string) ([]fetcher.ErrorClass, error) {
+	classes := make([]fetcher.ErrorClass, 0, len(names))
+	for _, name := range names {
+		class, err := fetcher.ParseErrorClass(name)
+		if err != nil {
+			return nil, err
+		}
+
+		classes = append(classes, class)
+	}
+
+	return classes, nil
+}
+
+// newAssetFetcher returns a fetcher with Options.AssetRetry, or pageFetch when it is not set.
+func newAssetFetcher(
+	opts Options,
+	pageFetch *fetcher.Fetcher,
+	limits *limiter.Registry,
+	breakers *breaker.Registry,
+) *fetcher.Fetcher {
+	if opts.AssetRetry == nil {
+		return pageFetch
+	}
+
+	return fetcher.New(
+		opts.HTTPClient,
+		opts.Timeout,
+		opts.UserAgent,
+		limits,
+		breakers,
+		fetcherRetryPolicy(*opts.AssetRetry, opts),
+		opts.Clock,
+	)
+}
@@ -0,0 +1,82 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_RetryPolicy_AssetsUseSeparatePolicy(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	client, tracker := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body><a href="/deploying"></a><img src="/logo.png"></body></html>`
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+		routeID("https", "example.com", "/deploying"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusBadGateway, "deploying", nil), nil
+		},
+		routeID("https", "example.com", "/logo.png"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusBadGateway, "deploying", nil), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, clock)
+	opts.Retry = &RetryPolicy{Retries: 3, StatusCodes: []int{http.StatusBadGateway}}
+	opts.AssetRetry = &RetryPolicy{Retries: 1}
+
+	_, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.Equal(t, 4, tracker.countHostPath("example.com", "/deploying"))
+	require.Equal(t, 2, tracker.countHostPath("example.com", "/logo.png"))
+}
+
+func TestSpec_RetryPolicy_BudgetIsSharedAcrossTheCrawl(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	failing := func(req *http.Request) (*http.Response, error) {
+		return responseForRequest(req, http.StatusServiceUnavailable, "down", nil), nil
+	}
+	client, tracker := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body><a href="/a"></a><a href="/b"></a><a href="/c"></a></body></html>`
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+		routeID("https", "example.com", "/a"): failing,
+		routeID("https", "example.com", "/b"): failing,
+		routeID("https", "example.com", "/c"): failing,
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, clock)
+	opts.Retry = &RetryPolicy{Retries: 5, Budget: 2}
+
+	_, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	total := 0
+	for _, path := range []string{"/a", "/b", "/c"} {
+		total += tracker.countHostPath("example.com", path)
+	}
+
+	require.Equal(t, 3+2, total)
+}
+
+func TestSpec_RetryPolicy_UnknownErrorClassFails(t *testing.T) {
+	t.Parallel()
+
+	client, tracker := newTrackedClient(t, map[string]roundTripResponder{})
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, &testClock{now: fixtureTime})
+	opts.AssetRetry = &RetryPolicy{Retries: 1, ErrorClasses: []string{"timeout", "eof"}}
+
+	_, err := analyzeReport(context.Background(), opts)
+	require.ErrorContains(t, err, "invalid retry error class")
+	require.ErrorContains(t, err, `"eof"`)
+	require.Zero(t, tracker.countHostPath("example.com", "/"))
+}
@@ -0,0 +1,125 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"code/internal/fetcher"
+	"code/internal/limiter"
+	"code/internal/robots"
+)
+
+const (
+	statusBlocked      = "blocked_by_robots"
+	blockedByRobotsErr = "disallowed by robots.txt"
+	// robotsMaxBytes is the part of robots.txt that is read; RFC 9309 requires at least 500 KiB.
+	robotsMaxBytes = 500 << 10
+)
+
+type robotsEntry struct {
+	rules *robots.Rules
+	ready chan struct{}
+}
+
+// robotsRegistry loads and caches robots.txt rules per origin.
+// Rules are enforced only when enforce is set; sitemap discovery reads them either way.
+// A nil registry allows every URL.
+type robotsRegistry struct {
+	fetch     *fetcher.Fetcher
+	limits    *limiter.Registry
+	userAgent string
+	enforce   bool
+	mu        sync.Mutex
+	entries   map[string]*robotsEntry
+}
+
+func newRobotsRegistry(opts Options, fetch *fetcher.Fetcher, limits *limiter.Registry) *robotsRegistry {
+	if !opts.RespectRobots && !opts.UseSitemap {
+		return nil
+	}
+
+	return &robotsRegistry{
+		fetch:     fetch.WithLimits(bodyLimits(robotsMaxBytes, opts)),
+		limits:    limits,
+		userAgent: opts.UserAgent,
+		enforce:   opts.RespectRobots,
+		entries:   map[string]*robotsEntry{},
+	}
+}
+
+// allowed reports whether rawURL may be crawled according to its origin's robots.txt.
+func (r *robotsRegistry) allowed(ctx context.Context, rawURL string) bool {
+	if r == nil || !r.enforce {
+		return true
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return true
+	}
+
+	return r.rulesFor(ctx, parsed).Allowed(parsed.RequestURI())
+}
+
+func (r *robotsRegistry) rulesFor(ctx context.Context, target *url.URL) *robots.Rules {
+	if r == nil {
+		return nil
+	}
+
+	origin := target.Scheme + "://" + target.Host
+
+	r.mu.Lock()
+	if cached, ok := r.entries[origin]; ok {
+		r.mu.Unlock()
+
+		select {
+		case <-cached.ready:
+			return cached.rules
+		case <-ctx.Done():
+			return robots.AllowAll()
+		}
+	}
+
+	entry := &robotsEntry{ready: make(chan struct{})}
+	r.entries[origin] = entry
+	r.mu.Unlock()
+
+	entry.rules = r.load(ctx, origin)
+	if r.enforce {
+		r.limits.Raise(target.Host, entry.rules.CrawlDelay())
+	}
+	close(entry.ready)
+
+	return entry.rules
+}
+
+// load fetches robots.txt following RFC 9309: a 4xx response allows everything,
+// while a server or network error disallows the whole origin.
+func (r *robotsRegistry) load(ctx context.Context, origin string) *robots.Rules {
+	result, err := r.fetch.Fetch(ctx, origin+"/robots.txt")
+
+	switch {
+	case err == nil:
+		return robots.Parse(result.Body, r.userAgent)
+	case ctx.Err() != nil:
+		return robots.AllowAll()
+	case result.StatusCode >= http.StatusBadRequest && result.StatusCode < http.StatusInternalServerError:
+		return robots.AllowAll()
+	default:
+		return robots.DisallowAll()
+	}
+}
+
+func blockedPageResult(job crawlJob, page Page) pageResult {
+	page.Status = statusBlocked
+	page.Error = blockedByRobotsErr
+	page.BrokenLinks = nil
+	page.Assets = nil
+
+	return pageResult{
+		job:  job,
+		page: page,
+	}
+}
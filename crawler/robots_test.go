@@ -0,0 +1,112 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_Robots_DisallowedPagesAreReportedAndNotFetched(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	routes := map[string]roundTripResponder{
+		routeID("https", "example.com", "/robots.txt"): func(req *http.Request) (*http.Response, error) {
+			body := "User-agent: test-agent\nDisallow: /private\n\nUser-agent: *\nDisallow: /\n"
+			return responseForRequest(req, http.StatusOK, body, nil), nil
+		},
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body><a href="/private/a">p</a><a href="/public">c</a></body></html>`
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+		routeID("https", "example.com", "/public"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "<html><body>ok</body></html>", nil), nil
+		},
+	}
+	client, calls := newTrackedClient(t, routes)
+
+	opts := optionsForContract(fixtureBaseURL, 2, 0, client, clock)
+	opts.RespectRobots = true
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+	require.Len(t, report.Pages, 3)
+
+	blocked := findPageByPath(t, report, "/private/a")
+	require.NotNil(t, blocked)
+	require.Equal(t, statusBlocked, blocked.Status)
+	require.Equal(t, blockedByRobotsErr, blocked.Error)
+	require.Zero(t, blocked.HTTPStatus)
+
+	public := findPageByPath(t, report, "/public")
+	require.NotNil(t, public)
+	require.Equal(t, statusOK, public.Status)
+
+	require.Equal(t, 1, calls.countHostPath("example.com", "/robots.txt"))
+	require.Zero(t, calls.countHostPath("example.com", "/private/a"))
+}
+
+func TestSpec_Robots_CrawlDelayRaisesInterval(t *testing.T) {
+	t.Parallel()
+
+	clock := &rateClock{now: fixtureTime}
+	pageTimes := &requestRecorder{}
+	routes := map[string]roundTripResponder{
+		routeID("https", "example.com", "/robots.txt"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "User-agent: *\nCrawl-delay: 1\n", nil), nil
+		},
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			pageTimes.record(clock.Now())
+			body := `<html><body><a href="/a">a</a></body></html>`
+			return responseForRequest(req, http.StatusOK, body, http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+		routeID("https", "example.com", "/a"): func(req *http.Request) (*http.Response, error) {
+			pageTimes.record(clock.Now())
+			return responseForRequest(req, http.StatusOK, "<html><body>ok</body></html>", nil), nil
+		},
+	}
+	client, _ := newTrackedClient(t, routes)
+
+	opts := Options{
+		URL:           fixtureBaseURL,
+		Depth:         1,
+		Concurrency:   1,
+		RPS:           5,
+		Timeout:       time.Second,
+		UserAgent:     "test-agent",
+		RespectRobots: true,
+		HTTPClient:    client,
+		Clock:         clock,
+	}
+
+	_, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	times := pageTimes.snapshot()
+	require.Len(t, times, 2)
+	require.Equal(t, time.Second, times[1].Sub(times[0]))
+}
+
+func TestSpec_Robots_ServerErrorDisallowsOrigin(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	routes := map[string]roundTripResponder{
+		routeID("https", "example.com", "/robots.txt"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusServiceUnavailable, "down", nil), nil
+		},
+	}
+	client, calls := newTrackedClient(t, routes)
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, clock)
+	opts.RespectRobots = true
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+	require.Len(t, report.Pages, 1)
+	require.Equal(t, statusBlocked, report.Pages[0].Status)
+	require.Zero(t, calls.countHostPath("example.com", "/"))
+}
@@ -0,0 +1,79 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+
+	"code/internal/scope"
+)
+
+// siteScope decides which URLs belong to the crawled site, which of them are crawled,
+// and which links are checked.
+type siteScope struct {
+	sites *scope.Sites
+	crawl *scope.Patterns
+	check *scope.Patterns
+	links scope.LinkCheck
+}
+
+func newSiteScope(opts Options, baseURL *url.URL) (siteScope, error) {
+	mode, err := scope.ParseMode(opts.Scope)
+	if err != nil {
+		return siteScope{}, fmt.Errorf("invalid scope: %w", err)
+	}
+
+	sites, err := scope.NewSites(mode, baseURL, opts.AllowedOrigins, opts.IgnoreScheme)
+	if err != nil {
+		return siteScope{}, fmt.Errorf("invalid scope: %w", err)
+	}
+
+	crawl, err := scope.NewPatterns(opts.Include, opts.Exclude)
+	if err != nil {
+		return siteScope{}, fmt.Errorf("invalid crawl pattern: %w", err)
+	}
+
+	check, err := scope.NewPatterns(opts.CheckInclude, opts.CheckExclude)
+	if err != nil {
+		return siteScope{}, fmt.Errorf("invalid link check pattern: %w", err)
+	}
+
+	links, err := scope.ParseLinkCheck(opts.LinkCheck)
+	if err != nil {
+		return siteScope{}, fmt.Errorf("invalid link check mode: %w", err)
+	}
+
+	return siteScope{sites: sites, crawl: crawl, check: check, links: links}, nil
+}
+
+// exactOriginScope accepts the origin of baseURL and every link on it.
+func exactOriginScope(baseURL *url.URL) siteScope {
+	return siteScope{sites: scope.ExactOrigin(baseURL), links: scope.LinkCheckAll}
+}
+
+// sameSite reports whether rawURL is on the crawled site.
+func (s siteScope) sameSite(rawURL string) bool {
+	return s.sites.Contains(rawURL)
+}
+
+// allows reports whether rawURL is on the site and passes the include and exclude patterns.
+func (s siteScope) allows(rawURL string) bool {
+	return s.sameSite(rawURL) && s.crawl.Match(rawURL)
+}
+
+// checks reports whether a link to rawURL is checked. It does not decide what is crawled.
+func (s siteScope) checks(rawURL string) bool {
+	return s.check.Match(rawURL)
+}
+
+// fetchesLink reports whether the link check mode fetches a link to rawURL. Links that
+// are not fetched are treated as working, so links on the site are still crawled.
+func (s siteScope) fetchesLink(rawURL string) bool {
+	switch s.links {
+	case scope.LinkCheckNone:
+		return false
+	case scope.LinkCheckInternal:
+		return s.sameSite(rawURL)
+	default:
+		return true
+	}
+}
@@ -0,0 +1,189 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_Scope_IncludeExcludePatterns(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	page := func(req *http.Request) (*http.Response, error) {
+		return responseForRequest(req, http.StatusOK, `<html></html>`, html), nil
+	}
+	client, tracker := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body>
+				<a href="/docs/a"></a><a href="/admin/users"></a><a href="/search?q=shoes"></a>
+				<a href="/calendar/2024/01"></a><a href="https://other.example/logout"></a>
+			</body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "example.com", "/docs/a"):           page,
+		routeID("https", "example.com", "/admin/users"):      page,
+		routeID("https", "example.com", "/search"):           page,
+		routeID("https", "example.com", "/calendar/2024/01"): page,
+		routeID("https", "other.example", "/logout"):         page,
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, clock)
+	opts.Exclude = []string{"/admin*", "/search?q=*", `re:^/calendar/\d+`}
+	opts.CheckExclude = []string{"/logout"}
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	urls := make([]string, 0, len(report.Pages))
+	for _, page := range report.Pages {
+		urls = append(urls, page.URL)
+	}
+	require.ElementsMatch(t, []string{"https://example.com", "https://example.com/docs/a"}, urls)
+
+	// Crawl patterns do not stop link checks; check patterns do.
+	require.Equal(t, 1, tracker.countHostPath("example.com", "/admin/users"))
+	require.Zero(t, tracker.countHostPath("other.example", "/logout"))
+}
+
+func TestSpec_Scope_CheckExcludedLinksAreStillCrawled(t *testing.T) {
+	t.Parallel()
+
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	client, tracker := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body><a href="/reports/2024"></a><a href="/gone"></a></body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "example.com", "/reports/2024"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, `<html><body><a href="/reports/2023"></a></body></html>`, html), nil
+		},
+		routeID("https", "example.com", "/reports/2023"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, `<html></html>`, html), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 3, 0, client, &testClock{now: fixtureTime})
+	opts.CheckExclude = []string{"/reports/*", "/gone"}
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.ElementsMatch(t, []string{
+		"https://example.com",
+		"https://example.com/gone",
+		"https://example.com/reports/2024",
+		"https://example.com/reports/2023",
+	}, pageURLs(report))
+	require.Equal(t, 1, tracker.countHostPath("example.com", "/reports/2024"))
+	require.Empty(t, findPageByPath(t, report, "/").BrokenLinks, "excluded links are not checked")
+}
+
+func TestSpec_Scope_InvalidPatternFails(t *testing.T) {
+	t.Parallel()
+
+	client, tracker := newTrackedClient(t, map[string]roundTripResponder{})
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, &testClock{now: fixtureTime})
+	opts.Include = []string{"re:[a-"}
+
+	_, err := analyzeReport(context.Background(), opts)
+	require.ErrorContains(t, err, "invalid crawl pattern")
+	require.Zero(t, tracker.countHostPath("example.com", "/"))
+}
+
+func scopeFixtureClient(t *testing.T) (*http.Client, *callTracker) {
+	t.Helper()
+
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	page := func(req *http.Request) (*http.Response, error) {
+		return responseForRequest(req, http.StatusOK, `<html></html>`, html), nil
+	}
+
+	return newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body>
+				<a href="https://docs.example.com/guide"></a><a href="https://blog.example.com/post"></a>
+				<a href="http://example.com/legacy"></a><a href="https://other.example/page"></a>
+			</body></html>`
+			return responseForRequest(req, http.StatusOK, body, html), nil
+		},
+		routeID("https", "docs.example.com", "/guide"): page,
+		routeID("https", "blog.example.com", "/post"):  page,
+		routeID("http", "example.com", "/legacy"):      page,
+		routeID("https", "other.example", "/page"):     page,
+	})
+}
+
+func TestSpec_Scope_Modes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		scope        string
+		allowed      []string
+		ignoreScheme bool
+		want         []string
+	}{
+		{
+			name:  "origin",
+			scope: "origin",
+			want:  []string{"https://example.com"},
+		},
+		{
+			name:  "domain",
+			scope: "domain",
+			want:  []string{"https://example.com", "https://docs.example.com/guide", "https://blog.example.com/post"},
+		},
+		{
+			name:    "list",
+			scope:   "list",
+			allowed: []string{"https://docs.example.com"},
+			want:    []string{"https://example.com", "https://docs.example.com/guide"},
+		},
+		{
+			name:         "ignore scheme",
+			ignoreScheme: true,
+			want:         []string{"https://example.com", "http://example.com/legacy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client, tracker := scopeFixtureClient(t)
+			opts := optionsForContract(fixtureBaseURL, 3, 0, client, &testClock{now: fixtureTime})
+			opts.Scope = tt.scope
+			opts.AllowedOrigins = tt.allowed
+			opts.IgnoreScheme = tt.ignoreScheme
+
+			report, err := analyzeReport(context.Background(), opts)
+			require.NoError(t, err)
+
+			urls := make([]string, 0, len(report.Pages))
+			for _, page := range report.Pages {
+				urls = append(urls, page.URL)
+			}
+			require.ElementsMatch(t, tt.want, urls)
+
+			// Links outside the scope are still checked, but never crawled.
+			require.Equal(t, 1, tracker.countHostPath("other.example", "/page"))
+		})
+	}
+}
+
+func TestSpec_Scope_InvalidModeFails(t *testing.T) {
+	t.Parallel()
+
+	client, _ := newTrackedClient(t, map[string]roundTripResponder{})
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, &testClock{now: fixtureTime})
+	opts.Scope = "galaxy"
+
+	_, err := analyzeReport(context.Background(), opts)
+	require.ErrorContains(t, err, "invalid scope")
+}
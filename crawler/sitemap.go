@@ -0,0 +1,122 @@
+package crawler
+
+import (
+	"context"
+
+	"code/internal/sitemap"
+)
+
+const (
+	sourceRoot      = "root"
+	sourceSitemap   = "sitemap"
+	sourceLink      = "link"
+	maxSitemapFiles = 100
+)
+
+func (a *analyzer) pageSource(job crawlJob) string {
+	if !a.options.UseSitemap {
+		return ""
+	}
+
+	return job.source
+}
+
+// seedFromSitemaps queues every sitemap URL in the crawl scope at depth 1, as if the root
+// linked to it.
+func (a *analyzer) seedFromSitemaps(ctx context.Context, agg *aggregator) {
+	a.sitemap = a.loadSitemapURLs(ctx)
+
+	for _, pageURL := range a.sitemap {
+		agg.enqueue(ctx, crawlJob{
+			url:          pageURL,
+			depth:        1,
+			source:       sourceSitemap,
+			discoveredAt: a.options.Clock.Now(),
+		})
+	}
+}
+
+// loadSitemapURLs walks sitemap indexes breadth-first, visiting at most maxSitemapFiles files.
+// Sitemaps named in robots.txt are trusted wherever they are; sitemaps listed in an index
+// are fetched only if they are on the crawled site.
+func (a *analyzer) loadSitemapURLs(ctx context.Context) []string {
+	queue := a.sitemapLocations(ctx)
+	visited := map[string]bool{}
+	pages := []string{}
+
+	for len(queue) > 0 && len(visited) < maxSitemapFiles {
+		location := queue[0]
+		queue = queue[1:]
+
+		if visited[location] {
+			continue
+		}
+
+		visited[location] = true
+
+		doc, ok := a.fetchSitemap(ctx, location)
+		if !ok {
+			continue
+		}
+
+		queue = append(queue, a.scopeSitemapFiles(doc.Sitemaps)...)
+		pages = append(pages, a.scopeSitemapURLs(doc.URLs)...)
+	}
+
+	return pages
+}
+
+func (a *analyzer) sitemapLocations(ctx context.Context) []string {
+	locations := a.robots.rulesFor(ctx, a.baseURL).Sitemaps()
+	if len(locations) > 0 {
+		return locations
+	}
+
+	return []string{a.baseURL.Scheme + "://" + a.baseURL.Host + "/sitemap.xml"}
+}
+
+// fetchSitemap reads at most sitemap.MaxUncompressedBytes of a sitemap; a longer one is skipped.
+// sitemap.Parse applies the same cap again after decompressing a gzip file.
+func (a *analyzer) fetchSitemap(ctx context.Context, location string) (sitemap.Document, bool) {
+	result, err := a.sitemapFetch.Fetch(ctx, location)
+	if err != nil || result.Truncated {
+		return sitemap.Document{}, false
+	}
+
+	doc, err := sitemap.Parse(result.Body)
+	if err != nil {
+		return sitemap.Document{}, false
+	}
+
+	return doc, true
+}
+
+func (a *analyzer) scopeSitemapFiles(locations []string) []string {
+	scoped := make([]string, 0, len(locations))
+
+	for _, location := range locations {
+		resolved, ok := a.normalizer.Resolve(a.baseURL, location)
+		if !ok || !a.site.sameSite(resolved) {
+			continue
+		}
+
+		scoped = append(scoped, resolved)
+	}
+
+	return scoped
+}
+
+func (a *analyzer) scopeSitemapURLs(locations []string) []string {
+	scoped := make([]string, 0, len(locations))
+
+	for _, location := range locations {
+		resolved, ok := a.normalizer.Resolve(a.baseURL, location)
+		if !ok || !a.site.allows(resolved) {
+			continue
+		}
+
+		scoped = append(scoped, resolved)
+	}
+
+	return scoped
+}
@@ -0,0 +1,268 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_Sitemap_SeedsFromRobotsIndexAndGzip(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	htmlHeader := http.Header{"Content-Type": []string{"text/html"}}
+	routes := map[string]roundTripResponder{
+		routeID("https", "example.com", "/robots.txt"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "Sitemap: https://example.com/index.xml\n", nil), nil
+		},
+		routeID("https", "example.com", "/index.xml"): func(req *http.Request) (*http.Response, error) {
+			body := `<sitemapindex><sitemap><loc>https://example.com/pages.xml.gz</loc></sitemap></sitemapindex>`
+			return responseForRequest(req, http.StatusOK, body, nil), nil
+		},
+		routeID("https", "example.com", "/pages.xml.gz"): func(req *http.Request) (*http.Response, error) {
+			body := gzipString(t, `<urlset>
+				<url><loc>https://example.com/</loc></url>
+				<url><loc>https://example.com/deep/page</loc></url>
+				<url><loc>https://other.test/foreign</loc></url>
+			</urlset>`)
+			return responseForRequest(req, http.StatusOK, body, nil), nil
+		},
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			body := `<html><body><a href="/linked">l</a></body></html>`
+			return responseForRequest(req, http.StatusOK, body, htmlHeader), nil
+		},
+		routeID("https", "example.com", "/linked"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "<html><body>ok</body></html>", htmlHeader), nil
+		},
+		routeID("https", "example.com", "/deep/page"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "<html><body>deep</body></html>", htmlHeader), nil
+		},
+	}
+	client, calls := newTrackedClient(t, routes)
+
+	opts := optionsForContract(fixtureBaseURL, 2, 0, client, clock)
+	opts.UseSitemap = true
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+	require.Len(t, report.Pages, 3)
+
+	sources := map[string]string{}
+	for _, page := range report.Pages {
+		sources[page.URL] = page.Source
+	}
+
+	require.Equal(t, map[string]string{
+		"https://example.com":           sourceRoot,
+		"https://example.com/deep/page": sourceSitemap,
+		"https://example.com/linked":    sourceLink,
+	}, sources)
+	require.Zero(t, calls.countHostPath("other.test", "/foreign"))
+	require.Zero(t, calls.countHostPath("example.com", "/sitemap.xml"))
+}
+
+func TestSpec_Sitemap_IndexDoesNotLeaveTheSite(t *testing.T) {
+	t.Parallel()
+
+	htmlHeader := http.Header{"Content-Type": []string{"text/html"}}
+	client, calls := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/sitemap.xml"): func(req *http.Request) (*http.Response, error) {
+			body := `<sitemapindex>
+				<sitemap><loc>https://other.test/pages.xml</loc></sitemap>
+				<sitemap><loc>https://example.com/pages.xml</loc></sitemap>
+			</sitemapindex>`
+			return responseForRequest(req, http.StatusOK, body, nil), nil
+		},
+		routeID("https", "example.com", "/pages.xml"): func(req *http.Request) (*http.Response, error) {
+			body := `<urlset><url><loc>https://example.com/listed</loc></url></urlset>`
+			return responseForRequest(req, http.StatusOK, body, nil), nil
+		},
+		routeID("https", "other.test", "/pages.xml"): func(req *http.Request) (*http.Response, error) {
+			body := `<urlset><url><loc>https://example.com/planted</loc></url></urlset>`
+			return responseForRequest(req, http.StatusOK, body, nil), nil
+		},
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "<html></html>", htmlHeader), nil
+		},
+		routeID("https", "example.com", "/listed"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "<html></html>", htmlHeader), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, &testClock{now: fixtureTime})
+	opts.UseSitemap = true
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.Zero(t, calls.countHostPath("other.test", "/pages.xml"))
+	require.ElementsMatch(t, []string{"https://example.com", "https://example.com/listed"}, pageURLs(report))
+}
+
+func TestSpec_Sitemap_FallsBackToSitemapXML(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	routes := map[string]roundTripResponder{
+		routeID("https", "example.com", "/sitemap.xml"): func(req *http.Request) (*http.Response, error) {
+			body := `<urlset><url><loc>https://example.com/only-in-sitemap</loc></url></urlset>`
+			return responseForRequest(req, http.StatusOK, body, nil), nil
+		},
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "<html><body>root</body></html>", nil), nil
+		},
+		routeID("https", "example.com", "/only-in-sitemap"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "<html><body>hidden</body></html>", nil), nil
+		},
+	}
+	client, _ := newTrackedClient(t, routes)
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, clock)
+	opts.UseSitemap = true
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	page := findPageByPath(t, report, "/only-in-sitemap")
+	require.NotNil(t, page)
+	require.Equal(t, sourceSitemap, page.Source)
+	require.Equal(t, 1, page.Depth)
+}
+
+func TestSpec_Sitemap_SeedsAreNotRootPages(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: fixtureTime}
+	routes := map[string]roundTripResponder{
+		routeID("https", "example.com", "/sitemap.xml"): func(req *http.Request) (*http.Response, error) {
+			body := `<urlset>
+				<url><loc>https://example.com/listed</loc></url>
+				<url><loc>https://example.com/gone</loc></url>
+			</urlset>`
+			return responseForRequest(req, http.StatusOK, body, nil), nil
+		},
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "<html><body>root</body></html>", nil), nil
+		},
+		routeID("https", "example.com", "/listed"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "<html><body>listed</body></html>", nil), nil
+		},
+	}
+	client, _ := newTrackedClient(t, routes)
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, clock)
+	opts.UseSitemap = true
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err, "a failing seed does not fail the crawl")
+
+	require.Equal(t, []int{1, 2}, report.Summary.PagesByDepth)
+	require.Equal(t, http.StatusNotFound, findPageByPath(t, report, "/gone").HTTPStatus)
+}
+
+// endlessBody serves prefix followed by filler bytes that never end.
+type endlessBody struct {
+	prefix *strings.Reader
+}
+
+func (b *endlessBody) Read(p []byte) (int, error) {
+	if b.prefix.Len() > 0 {
+		return b.prefix.Read(p)
+	}
+
+	for i := range p {
+		p[i] = ' '
+	}
+
+	return len(p), nil
+}
+
+func (b *endlessBody) Close() error { return nil }
+
+func TestSpec_Sitemap_EndlessBodiesAreCapped(t *testing.T) {
+	t.Parallel()
+
+	endless := func(prefix string) roundTripResponder {
+		return func(req *http.Request) (*http.Response, error) {
+			resp := responseForRequest(req, http.StatusOK, "", nil)
+			resp.Body = &endlessBody{prefix: strings.NewReader(prefix)}
+			resp.ContentLength = -1
+
+			return resp, nil
+		}
+	}
+
+	client, calls := newTrackedClient(t, map[string]roundTripResponder{
+		routeID("https", "example.com", "/robots.txt"): endless("Sitemap: https://example.com/huge.xml\n#"),
+		routeID("https", "example.com", "/huge.xml"):   endless(`<urlset><url><loc>https://example.com/seeded</loc></url>`),
+		routeID("https", "example.com", "/"): func(req *http.Request) (*http.Response, error) {
+			return responseForRequest(req, http.StatusOK, "<html></html>", http.Header{"Content-Type": []string{"text/html"}}), nil
+		},
+	})
+
+	opts := optionsForContract(fixtureBaseURL, 1, 0, client, &testClock{now: fixtureTime})
+	opts.UseSitemap = true
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	// robots.txt is read up to its cap and still names the sitemap; the sitemap is cut off
+	// at its cap and skipped.
+	require.Equal(t, 1, calls.countHostPath("example.com", "/huge.xml"))
+	require.Equal(t, []string{"https://example.com"}, pageURLs(report))
+}
+
+func TestSpec_Queue_ManyLinksDoNotStallSingleWorker(t *testing.T) {
+	t.Parallel()
+
+	var links strings.Builder
+	for i := range 200 {
+		fmt.Fprintf(&links, `<a href="/p%d">p</a>`, i)
+	}
+
+	client := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "" || req.URL.Path == "/" {
+				return responseForRequest(req, http.StatusOK, "<html><body>"+links.String()+"</body></html>", nil), nil
+			}
+
+			return responseForRequest(req, http.StatusOK, "<html><body>ok</body></html>", nil), nil
+		}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := Options{
+		URL:         fixtureBaseURL,
+		Depth:       2,
+		Concurrency: 1,
+		Timeout:     time.Second,
+		HTTPClient:  client,
+		Clock:       &testClock{now: fixtureTime},
+	}
+
+	report, err := analyzeReport(ctx, opts)
+	require.NoError(t, err)
+	require.NoError(t, ctx.Err())
+	require.Len(t, report.Pages, 201)
+}
+
+func gzipString(t *testing.T, value string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write([]byte(value))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return buf.String()
+}
@@ -0,0 +1,141 @@
+package crawler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code/internal/mediatype"
+)
+
+const noResponseClass = "none"
+
+// summarize computes the report totals from the crawled pages.
+func summarize(pages []Page, bytesTransferred int64, duration time.Duration) Summary {
+	summary := Summary{
+		Pages:            len(pages),
+		ByStatus:         map[string]int{},
+		ByStatusClass:    map[string]int{},
+		PagesByDepth:     []int{},
+		BytesTransferred: bytesTransferred,
+		DurationMs:       duration.Milliseconds(),
+	}
+
+	for _, page := range pages {
+		summary.ByStatus[page.Status]++
+		summary.ByStatusClass[statusClass(page.HTTPStatus)]++
+		summary.countDepth(page.Depth)
+		summary.BrokenLinks += len(page.BrokenLinks)
+		summary.BrokenAnchors += len(page.BrokenAnchors)
+		summary.BrokenAssets += countBrokenAssets(page.Assets)
+		summary.SEOIssues.add(page)
+	}
+
+	return summary
+}
+
+// statusClass returns "2xx" for 200 and so on, or "none" when no response was received.
+func statusClass(code int) string {
+	if code <= 0 {
+		return noResponseClass
+	}
+
+	return strconv.Itoa(code/100) + "xx"
+}
+
+func (s *Summary) countDepth(depth int) {
+	if depth < 0 {
+		return
+	}
+
+	for len(s.PagesByDepth) <= depth {
+		s.PagesByDepth = append(s.PagesByDepth, 0)
+	}
+
+	s.PagesByDepth[depth]++
+}
+
+func countBrokenAssets(assets []Asset) int {
+	broken := 0
+	for _, asset := range assets {
+		if asset.Error != "" || asset.StatusCode >= 400 {
+			broken++
+		}
+	}
+
+	return broken
+}
+
+// add counts the SEO issues of a page; only HTML pages that were fetched and parsed count.
+func (s *SEOIssues) add(page Page) {
+	if page.Status != statusOK || !mediatype.IsHTML(page.ContentType) {
+		return
+	}
+
+	if !page.SEO.HasTitle {
+		s.MissingTitle++
+	}
+
+	if !page.SEO.HasDescription {
+		s.MissingDescription++
+	}
+
+	if !page.SEO.HasH1 {
+		s.MissingH1++
+	}
+}
+
+// formatSummary writes the summary as human-readable text, one line per total.
+func formatSummary(rootURL string, summary Summary) []byte {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "Site:             %s\n", rootURL)
+	fmt.Fprintf(&builder, "Pages:            %d\n", summary.Pages)
+	fmt.Fprintf(&builder, "By status:        %s\n", formatCounts(summary.ByStatus))
+	fmt.Fprintf(&builder, "By HTTP status:   %s\n", formatCounts(summary.ByStatusClass))
+	fmt.Fprintf(&builder, "Pages per depth:  %s\n", formatDepths(summary.PagesByDepth))
+	fmt.Fprintf(&builder, "Broken links:     %d\n", summary.BrokenLinks)
+	fmt.Fprintf(&builder, "Broken assets:    %d\n", summary.BrokenAssets)
+	fmt.Fprintf(&builder, "Broken anchors:   %d\n", summary.BrokenAnchors)
+	fmt.Fprintf(&builder, "SEO issues:       missing title %d, missing description %d, missing h1 %d\n",
+		summary.SEOIssues.MissingTitle, summary.SEOIssues.MissingDescription, summary.SEOIssues.MissingH1)
+	fmt.Fprintf(&builder, "Transferred:      %d bytes\n", summary.BytesTransferred)
+	fmt.Fprintf(&builder, "Duration:         %s\n", time.Duration(summary.DurationMs)*time.Millisecond)
+
+	return []byte(builder.String())
+}
+
+func formatCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "-"
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s %d", key, counts[key]))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func formatDepths(depths []int) string {
+	if len(depths) == 0 {
+		return "-"
+	}
+
+	parts := make([]string, 0, len(depths))
+	for depth, count := range depths {
+		parts = append(parts, fmt.Sprintf("%d: %d", depth, count))
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,108 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	pages := []Page{
+		{
+			Depth: 0, HTTPStatus: 200, Status: statusOK, ContentType: "text/html",
+			SEO:         SEO{HasTitle: true, HasH1: true},
+			BrokenLinks: []BrokenLink{{URL: "https://example.com/a"}, {URL: "https://example.com/b"}},
+			Assets:      []Asset{{StatusCode: 200}, {StatusCode: 404}, {Error: "timeout"}},
+		},
+		{Depth: 1, HTTPStatus: 200, Status: statusOK, ContentType: "application/pdf"},
+		{
+			Depth: 2, HTTPStatus: 200, Status: statusOK, ContentType: "text/html",
+			BrokenLinks:   []BrokenLink{{URL: "https://example.com/a"}},
+			BrokenAnchors: []BrokenAnchor{{URL: "https://example.com", Fragment: "x"}},
+		},
+		{Depth: 2, HTTPStatus: 404, Status: statusError},
+		{Depth: 2, Status: "blocked_by_robots"},
+	}
+
+	got := summarize(pages, 2048, 1500*time.Millisecond)
+
+	require.Equal(t, Summary{
+		Pages:            5,
+		ByStatus:         map[string]int{"ok": 3, "error": 1, "blocked_by_robots": 1},
+		ByStatusClass:    map[string]int{"2xx": 3, "4xx": 1, "none": 1},
+		PagesByDepth:     []int{1, 1, 3},
+		BrokenLinks:      3,
+		BrokenAssets:     2,
+		BrokenAnchors:    1,
+		SEOIssues:        SEOIssues{MissingTitle: 1, MissingDescription: 2, MissingH1: 1},
+		BytesTransferred: 2048,
+		DurationMs:       1500,
+	}, got)
+}
+
+func TestFormatSummary(t *testing.T) {
+	t.Parallel()
+
+	summary := Summary{
+		Pages:            3,
+		ByStatus:         map[string]int{"ok": 2, "error": 1},
+		ByStatusClass:    map[string]int{"2xx": 2, "5xx": 1},
+		PagesByDepth:     []int{1, 2},
+		BrokenLinks:      4,
+		BrokenAssets:     1,
+		SEOIssues:        SEOIssues{MissingDescription: 2},
+		BytesTransferred: 512,
+		DurationMs:       2500,
+	}
+
+	want := "Site:             https://example.com\n" +
+		"Pages:            3\n" +
+		"By status:        error 1, ok 2\n" +
+		"By HTTP status:   2xx 2, 5xx 1\n" +
+		"Pages per depth:  0: 1, 1: 2\n" +
+		"Broken links:     4\n" +
+		"Broken assets:    1\n" +
+		"Broken anchors:   0\n" +
+		"SEO issues:       missing title 0, missing description 2, missing h1 0\n" +
+		"Transferred:      512 bytes\n" +
+		"Duration:         2.5s\n"
+
+	require.Equal(t, want, string(formatSummary("https://example.com", summary)))
+}
+
+func TestSpec_Summary_CountsBytesAndDuration(t *testing.T) {
+	t.Parallel()
+
+	html := http.Header{"Content-Type": []string{"text/html"}}
+	rootBody := `<html><body><a href="/a">a</a></body></html>`
+	pageBody := `<html><title>A</title></html>`
+
+	clock := &rateClock{now: fixtureTime}
+	client := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/a" {
+				_ = clock.Sleep(req.Context(), 3*time.Second)
+
+				return responseForRequest(req, http.StatusOK, pageBody, html), nil
+			}
+
+			return responseForRequest(req, http.StatusOK, rootBody, html), nil
+		}),
+	}
+
+	opts := optionsForContract(fixtureBaseURL, 2, 0, client, nil)
+	opts.Clock = clock
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.Equal(t, 2, report.Summary.Pages)
+	require.Equal(t, []int{1, 1}, report.Summary.PagesByDepth)
+	require.Equal(t, int64(len(rootBody)+len(pageBody)), report.Summary.BytesTransferred)
+	require.Equal(t, int64(3000), report.Summary.DurationMs)
+}
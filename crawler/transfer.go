@@ -0,0 +1,101 @@
+package crawler
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+)
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// withTransferCount returns a copy of client that adds every byte it receives to total, so
+// the report can tell how much the crawl downloaded. With an *http.Transport the bytes are
+// counted on the connection, so compressed bodies count their size on the wire and headers
+// are included. Other transports do not expose their connections, and only the bodies they
+// return are counted.
+func withTransferCount(client *http.Client, total *atomic.Int64) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	counted := *client
+
+	if transport, ok := base.(*http.Transport); ok {
+		counted.Transport = countingConnections(transport, total)
+	} else {
+		counted.Transport = countingTransport{base: base, total: total}
+	}
+
+	return &counted
+}
+
+// countingConnections clones transport so that every connection it dials counts the bytes
+// read from it. TLS runs on top of the dialed connection unless DialTLSContext is set.
+func countingConnections(transport *http.Transport, total *atomic.Int64) *http.Transport {
+	counted := transport.Clone()
+
+	dial := counted.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	counted.DialContext = countingDial(dial, total)
+
+	if counted.DialTLSContext != nil {
+		counted.DialTLSContext = countingDial(counted.DialTLSContext, total)
+	}
+
+	return counted
+}
+
+func countingDial(dial dialFunc, total *atomic.Int64) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		return countingConn{Conn: conn, total: total}, nil
+	}
+}
+
+type countingConn struct {
+	net.Conn
+	total *atomic.Int64
+}
+
+func (c countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.total.Add(int64(n))
+
+	return n, err
+}
+
+type countingTransport struct {
+	base  http.RoundTripper
+	total *atomic.Int64
+}
+
+func (t countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if resp != nil && resp.Body != nil {
+		resp.Body = countingBody{ReadCloser: resp.Body, total: t.total}
+	}
+
+	return resp, err
+}
+
+type countingBody struct {
+	io.ReadCloser
+	total *atomic.Int64
+}
+
+func (b countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.total.Add(int64(n))
+
+	return n, err
+}
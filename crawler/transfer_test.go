@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTransferCount(t *testing.T) {
+	t.Parallel()
+
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return responseForRequest(req, http.StatusOK, "0123456789", nil), nil
+	})
+	client := &http.Client{Transport: transport}
+
+	var total atomic.Int64
+	counted := withTransferCount(client, &total)
+
+	for range 2 {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, fixtureBaseURL, nil)
+		require.NoError(t, err)
+
+		resp, err := counted.Do(req)
+		require.NoError(t, err)
+
+		_, err = io.Copy(io.Discard, resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	require.Equal(t, int64(20), total.Load())
+	require.IsType(t, roundTripFunc(nil), client.Transport, "the original client must not change")
+}
+
+func TestWithTransferCountCountsCompressedBytesOnTheWire(t *testing.T) {
+	t.Parallel()
+
+	page := strings.Repeat("<p>same paragraph</p>", 5000)
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write([]byte(page))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "gzip", r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write(compressed.Bytes())
+	}))
+	t.Cleanup(server.Close)
+
+	transport := &http.Transport{}
+	t.Cleanup(transport.CloseIdleConnections)
+
+	var total atomic.Int64
+	counted := withTransferCount(&http.Client{Transport: transport}, &total)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := counted.Do(req)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, page, string(body), "the transport must still decode the body")
+
+	require.Greater(t, total.Load(), int64(compressed.Len()), "headers are part of the transfer")
+	require.Less(t, total.Load(), int64(len(page)), "the compressed size must be counted")
+	require.Nil(t, transport.DialContext, "the original transport must not change")
+}
@@ -0,0 +1,69 @@
+package crawler
+
+import "code/internal/trap"
+
+// TrapRules configures crawler-trap detection. Zero fields use the defaults:
+// MaxRepeatedSegments 2 (how often one path segment may appear in a path), MaxURLLength 2000,
+// MaxQueryParams 10, and MaxNumericVariants 100 (how many URLs may differ only in their numbers).
+type TrapRules struct {
+	MaxRepeatedSegments int
+	MaxURLLength        int
+	MaxQueryParams      int
+	MaxNumericVariants  int
+}
+
+// trapTracker skips URLs that look like crawler traps and groups them for the report.
+// It belongs to the aggregator goroutine. A nil trapTracker flags nothing.
+type trapTracker struct {
+	detector *trap.Detector
+	traps    []Trap
+	index    map[string]int
+}
+
+func newTrapTracker(opts Options) *trapTracker {
+	if opts.Traps == nil {
+		return nil
+	}
+
+	return &trapTracker{
+		detector: trap.NewDetector(trap.Rules{
+			MaxRepeatedSegments: opts.Traps.MaxRepeatedSegments,
+			MaxURLLength:        opts.Traps.MaxURLLength,
+			MaxQueryParams:      opts.Traps.MaxQueryParams,
+			MaxNumericVariants:  opts.Traps.MaxNumericVariants,
+		}),
+		index: map[string]int{},
+	}
+}
+
+// flag reports whether rawURL looks like a trap and must not be queued.
+func (t *trapTracker) flag(rawURL string) bool {
+	if t == nil {
+		return false
+	}
+
+	rule, pattern := t.detector.Check(rawURL)
+	if rule == "" {
+		return false
+	}
+
+	key := rule + " " + pattern
+	if idx, ok := t.index[key]; ok {
+		t.traps[idx].Skipped++
+
+		return true
+	}
+
+	t.index[key] = len(t.traps)
+	t.traps = append(t.traps, Trap{Rule: rule, Pattern: pattern, Example: rawURL, Skipped: 1})
+
+	return true
+}
+
+func (t *trapTracker) report() []Trap {
+	if t == nil {
+		return nil
+	}
+
+	return t.traps
+}
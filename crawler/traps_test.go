@@ -0,0 +1,82 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// trapSiteClient serves an endless calendar, where every month links to the next one,
+// and a directory that links to itself one level deeper.
+func trapSiteClient() *http.Client {
+	html := http.Header{"Content-Type": []string{"text/html"}}
+
+	return &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			path := req.URL.EscapedPath()
+
+			var body string
+			switch {
+			case path == "" || path == "/":
+				body = `<a href="/calendar/1"></a><a href="/loop/"></a>`
+			case strings.HasPrefix(path, "/calendar/"):
+				month, _ := strconv.Atoi(strings.TrimPrefix(path, "/calendar/"))
+				body = fmt.Sprintf(`<a href="/calendar/%d"></a>`, month+1)
+			case strings.HasPrefix(path, "/loop/"):
+				body = `<a href="loop/"></a>`
+			}
+
+			return responseForRequest(req, http.StatusOK, `<html><body>`+body+`</body></html>`, html), nil
+		}),
+	}
+}
+
+func TestSpec_Traps_StopExpandingSuspiciousBranches(t *testing.T) {
+	t.Parallel()
+
+	opts := optionsForContract(fixtureBaseURL, 20, 0, trapSiteClient(), &testClock{now: fixtureTime})
+	opts.Traps = &TrapRules{MaxNumericVariants: 3}
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+
+	require.ElementsMatch(t, []string{
+		"https://example.com",
+		"https://example.com/calendar/1",
+		"https://example.com/calendar/2",
+		"https://example.com/calendar/3",
+		"https://example.com/loop/",
+		"https://example.com/loop/loop/",
+	}, pageURLs(report))
+
+	require.ElementsMatch(t, []Trap{
+		{
+			Rule:    "numeric_variants",
+			Pattern: "https://example.com/calendar/{n}",
+			Example: "https://example.com/calendar/4",
+			Skipped: 1,
+		},
+		{
+			Rule:    "repeated_segments",
+			Pattern: "https://example.com/loop/loop/loop/",
+			Example: "https://example.com/loop/loop/loop/",
+			Skipped: 1,
+		},
+	}, report.Traps)
+}
+
+func TestSpec_Traps_DisabledByDefault(t *testing.T) {
+	t.Parallel()
+
+	opts := optionsForContract(fixtureBaseURL, 5, 0, trapSiteClient(), &testClock{now: fixtureTime})
+
+	report, err := analyzeReport(context.Background(), opts)
+	require.NoError(t, err)
+	require.Nil(t, report.Traps)
+	require.NotNil(t, findPageByPath(t, report, "/loop/loop/loop/loop/"))
+}
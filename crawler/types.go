@@ -9,43 +9,259 @@ import (
 
 // Options configures crawler behavior.
 // Depth is the maximum crawl depth from the root (depth=1 includes root and children).
-// Delay and RPS control rate limiting; RPS overrides Delay.
-// Retries is the number of retries after the first attempt.
-// IndentJSON affects formatting only.
+// Scope selects the crawled site: "origin" (default) is the root's scheme, host and port,
+// "domain" adds every subdomain of the root's registrable domain, and "list" adds AllowedOrigins.
+// IgnoreScheme treats http and https URLs of an in-scope host as the same site.
+// URLs are normalized the same way everywhere in the crawl: the scheme and host are lowercased
+// unless KeepURLCase is set, default ports are dropped unless KeepDefaultPorts is set, and
+// fragments are removed. StripTrackingParams removes utm_*, fbclid and gclid query parameters;
+// StripParams removes more, where a trailing "*" matches any suffix. SortQuery orders query
+// parameters by name. TrailingSlash is "keep" (default: /docs and /docs/ are different
+// pages), "ignore" (they are one page) or "strip" (/docs/ is fetched as /docs).
+// Include and Exclude limit which in-scope pages are queued; CheckInclude and CheckExclude
+// limit which links are checked, without changing what is crawled. Patterns match the path and query: a glob where "*"
+// matches anything, or a regular expression prefixed with "re:". The root page is always crawled.
+// LinkCheck is "all" (default: links on the site and to other sites are checked), "internal"
+// (only links on the site) or "none" (no links are checked, links on the site are still crawled).
+// Links to other sites are checked by their own pool of ExternalConcurrency workers (default:
+// the size of the pool for links on the site, at most 2) with ExternalTimeout per request
+// (default: Timeout). Each external URL is checked once per crawl.
+// CheckAnchors keeps the fragment of links on the site and reports a link as a broken anchor
+// when the page it points to has no element with that id, or <a> with that name.
+// Delay and RPS set a global rate ceiling shared by all hosts; RPS overrides Delay.
+// PerHostRPS limits each host separately and HostRPS overrides it for specific hosts.
+// Burst > 1 switches every limiter to a token bucket that allows up to Burst requests at once.
+// AdaptiveRate widens a host's interval on 429/503 or latency spikes and narrows it again
+// on healthy responses, never going below the configured rate.
+// Retries is the number of retries after the first attempt; Retry replaces it with a full policy.
+// AssetRetry applies to asset checks only; when nil, assets use the page policy and its budget.
+// HeadChecks checks assets, links to other origins and links on the site that are not crawled
+// with HEAD, falling back to GET when the server does not support HEAD or the response lacks
+// Content-Length.
+// SkipNonHTMLBodies does not download page bodies whose Content-Type declares a non-HTML type.
+// MaxBodyBytes and MaxAssetBytes cap how much of a page or asset body is read, and
+// MaxDecompressedBytes caps a body after gzip decoding; zero means no limit.
+// MaxPages, MaxDuration and MaxURLsPerPathPrefix are crawl budgets; zero means no limit.
+// MaxPages caps the pages queued, MaxDuration the time since the crawl started, and
+// MaxURLsPerPathPrefix the pages queued from one directory, such as /products/shoes/.
+// When a budget runs out no new pages are queued, pages already being fetched finish,
+// and Report.Budget tells which budget ran out.
+// Traps enables crawler-trap detection: links that repeat path segments, are too long, have
+// too many query parameters, or are one of too many URLs differing only in numbers are not
+// queued and are listed in Report.Traps. Nil disables it.
+// MaxRedirectHops flags redirect chains with more hops as too long (default 5).
+// CircuitBreakerThreshold > 0 makes requests to a host fail fast after that many consecutive
+// transport failures; after CircuitBreakerCooldown (default 30s) one probe request is let through.
+// IndentJSON affects formatting only. SummaryOnly makes Analyze return the report summary
+// as human-readable text instead of the JSON report.
+// RespectRobots enables robots.txt checks; disallowed pages are reported as blocked
+// and Crawl-delay raises the rate limiting interval.
+// RespectNofollow reads <meta name="robots"> and X-Robots-Tag: links marked rel="nofollow"
+// and links on nofollow pages are checked but not crawled, and noindex pages are flagged.
+// UseSitemap seeds the crawl with URLs from robots.txt Sitemap lines or /sitemap.xml.
 type Options struct {
-	URL                string
-	Depth              int
-	Retries            int
-	Delay              time.Duration
-	Timeout            time.Duration
-	RPS                float64
-	UserAgent          string
-	Concurrency        int
-	MaxConcurrentFetch int
-	IndentJSON         bool
-	HTTPClient         *http.Client
-	Clock              limiter.Timer
+	URL                     string
+	Depth                   int
+	Scope                   string
+	AllowedOrigins          []string
+	IgnoreScheme            bool
+	StripTrackingParams     bool
+	StripParams             []string
+	SortQuery               bool
+	TrailingSlash           string
+	KeepURLCase             bool
+	KeepDefaultPorts        bool
+	Include                 []string
+	Exclude                 []string
+	CheckInclude            []string
+	CheckExclude            []string
+	LinkCheck               string
+	ExternalConcurrency     int
+	ExternalTimeout         time.Duration
+	CheckAnchors            bool
+	Retries                 int
+	Retry                   *RetryPolicy
+	AssetRetry              *RetryPolicy
+	HeadChecks              bool
+	SkipNonHTMLBodies       bool
+	MaxBodyBytes            int64
+	MaxAssetBytes           int64
+	MaxDecompressedBytes    int64
+	MaxRedirectHops         int
+	MaxPages                int
+	MaxDuration             time.Duration
+	MaxURLsPerPathPrefix    int
+	Traps                   *TrapRules
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
+	Delay                   time.Duration
+	Timeout                 time.Duration
+	RPS                     float64
+	PerHostRPS              float64
+	HostRPS                 map[string]float64
+	Burst                   int
+	AdaptiveRate            bool
+	UserAgent               string
+	Concurrency             int
+	MaxConcurrentFetch      int
+	IndentJSON              bool
+	SummaryOnly             bool
+	RespectRobots           bool
+	RespectNofollow         bool
+	UseSitemap              bool
+	HTTPClient              *http.Client
+	Clock                   limiter.Timer
 }
 
 // Report is the JSON report returned by Analyze.
+// Orphans is present only in sitemap mode; RateControl only with AdaptiveRate;
+// CircuitBreakers only when a host's circuit opened; Budget only when a crawl budget ran out;
+// Traps only when trap detection skipped a URL.
+// Summary holds the totals of the crawl.
+// BrokenLinks indexes the broken links of all pages: each URL once, with every page that
+// links to it; it is omitted when there are none.
 type Report struct {
-	RootURL     string `json:"root_url"`
-	Depth       int    `json:"depth"`
-	GeneratedAt string `json:"generated_at"`
-	Pages       []Page `json:"pages"`
+	RootURL         string            `json:"root_url"`
+	Depth           int               `json:"depth"`
+	GeneratedAt     string            `json:"generated_at"`
+	Summary         Summary           `json:"summary"`
+	Pages           []Page            `json:"pages"`
+	BrokenLinks     []BrokenLinkEntry `json:"broken_links,omitempty"`
+	Orphans         *Orphans          `json:"orphans,omitempty"`
+	RateControl     []HostRate        `json:"rate_control,omitempty"`
+	CircuitBreakers []HostCircuit     `json:"circuit_breakers,omitempty"`
+	Budget          *CrawlBudget      `json:"budget,omitempty"`
+	Traps           []Trap            `json:"traps,omitempty"`
+}
+
+// Summary totals the pages of a report.
+// ByStatus counts pages by Page.Status and ByStatusClass by HTTP status class, such as "2xx",
+// with "none" for pages that got no response. PagesByDepth[d] is the number of pages at depth d.
+// BrokenLinks, BrokenAssets and BrokenAnchors add up the entries of every page, so a URL broken
+// on several pages counts once per page. SEOIssues counts HTML pages fetched with status "ok".
+// BytesTransferred counts the bytes received for every response, including link checks, assets
+// and robots.txt, as they arrived on the connection; DurationMs is the time the crawl took.
+type Summary struct {
+	Pages            int            `json:"pages"`
+	ByStatus         map[string]int `json:"by_status"`
+	ByStatusClass    map[string]int `json:"by_status_class"`
+	PagesByDepth     []int          `json:"pages_by_depth"`
+	BrokenLinks      int            `json:"broken_links"`
+	BrokenAssets     int            `json:"broken_assets"`
+	BrokenAnchors    int            `json:"broken_anchors"`
+	SEOIssues        SEOIssues      `json:"seo_issues"`
+	BytesTransferred int64          `json:"bytes_transferred"`
+	DurationMs       int64          `json:"duration_ms"`
+}
+
+// SEOIssues counts pages missing a title, a meta description or an h1.
+type SEOIssues struct {
+	MissingTitle       int `json:"missing_title"`
+	MissingDescription int `json:"missing_description"`
+	MissingH1          int `json:"missing_h1"`
+}
+
+// BrokenLinkEntry is one broken URL in Report.BrokenLinks, sorted by URL.
+// Referrers lists every link element pointing to it, in page order.
+type BrokenLinkEntry struct {
+	URL        string         `json:"url"`
+	StatusCode int            `json:"status_code"`
+	Error      string         `json:"error,omitempty"`
+	Referrers  []LinkReferrer `json:"referrers"`
+}
+
+// LinkReferrer is a link element on a page: Text is its anchor text, or the alt or title
+// text when it has none, and Tag is "a" or "area".
+type LinkReferrer struct {
+	Page string `json:"page"`
+	Text string `json:"text"`
+	Tag  string `json:"tag"`
+}
+
+// Trap groups URLs that were not queued because they broke the same trap rule:
+// "repeated_segments", "url_length", "query_params" or "numeric_variants".
+// Pattern is the URL with digits replaced by "{n}"; Example is the first URL skipped
+// and Skipped counts all of them.
+type Trap struct {
+	Rule    string `json:"rule"`
+	Pattern string `json:"pattern"`
+	Example string `json:"example"`
+	Skipped int    `json:"skipped"`
+}
+
+// CrawlBudget tells which budget stopped the crawl: "max_pages", "max_duration", or
+// "max_urls_per_path_prefix" when only directory limits were hit.
+// Queued counts discovered URLs that were not crawled because of a budget.
+// PathPrefixes lists the directories that hit MaxURLsPerPathPrefix.
+type CrawlBudget struct {
+	Exhausted    string   `json:"exhausted"`
+	Queued       int      `json:"queued"`
+	PathPrefixes []string `json:"path_prefixes,omitempty"`
+}
+
+// HostCircuit summarizes the circuit breaker of one host.
+// Trips counts how often the circuit opened; Rejected counts requests that failed fast.
+type HostCircuit struct {
+	Host     string `json:"host"`
+	State    string `json:"state"`
+	Trips    int    `json:"trips"`
+	Rejected int    `json:"rejected"`
+}
+
+// HostRate describes the adaptive rate used for one host.
+// Intervals are in milliseconds; 0 means the host was not limited.
+type HostRate struct {
+	Host            string `json:"host"`
+	FinalIntervalMs int64  `json:"final_interval_ms"`
+	MaxIntervalMs   int64  `json:"max_interval_ms"`
+	Backoffs        int    `json:"backoffs"`
+}
+
+// Orphans compares sitemap URLs with the link graph.
+// SitemapOnly lists sitemap URLs no crawled page links to; NotInSitemap lists
+// successfully crawled pages the sitemap does not mention.
+type Orphans struct {
+	SitemapOnly  []string `json:"sitemap_only"`
+	NotInSitemap []string `json:"not_in_sitemap"`
 }
 
 // Page describes a crawled page.
+// Source is set only in sitemap mode and tells whether the page is the root,
+// a sitemap seed, or was found through links.
+// ContentType is the media type from the Content-Type header, or sniffed from the body when
+// the header is missing or generic. Only HTML and XHTML pages are parsed; other pages are
+// listed with SizeBytes and no links or assets.
+// Truncated means the body hit MaxBodyBytes and only the part read was parsed.
+// Redirect is set when the page URL redirected; HTTPStatus and the content are those of the final URL.
+// A page whose redirect leaves the site is not parsed. Pages that end at the same final URL are
+// crawled once: the first page to reach it keeps its entry and lists the other page URLs as
+// Aliases, which are not parsed and whose links are not checked.
+// BrokenAnchors is set with Options.CheckAnchors and lists links whose target page was
+// fetched but has no element matching the fragment; these are not in BrokenLinks.
+// NoIndex and NoFollow are set with Options.RespectNofollow when the page's meta robots tags or
+// X-Robots-Tag headers say so.
+// Encoding is the character set an HTML page was decoded from before parsing, detected from
+// a byte order mark, the Content-Type charset or a <meta charset> declaration.
 type Page struct {
-	URL          string       `json:"url"`
-	Depth        int          `json:"depth"`
-	HTTPStatus   int          `json:"http_status"`
-	Status       string       `json:"status"`
-	Error        string       `json:"error,omitempty"`
-	SEO          SEO          `json:"seo"`
-	BrokenLinks  []BrokenLink `json:"broken_links"`
-	Assets       []Asset      `json:"assets"`
-	DiscoveredAt string       `json:"discovered_at"`
+	URL           string         `json:"url"`
+	Depth         int            `json:"depth"`
+	HTTPStatus    int            `json:"http_status"`
+	ContentType   string         `json:"content_type"`
+	Encoding      string         `json:"encoding,omitempty"`
+	Status        string         `json:"status"`
+	Source        string         `json:"source,omitempty"`
+	Error         string         `json:"error,omitempty"`
+	SizeBytes     int64          `json:"size_bytes,omitempty"`
+	Truncated     bool           `json:"truncated,omitempty"`
+	Redirect      *Redirect      `json:"redirect,omitempty"`
+	Aliases       []string       `json:"aliases,omitempty"`
+	NoIndex       bool           `json:"noindex,omitempty"`
+	NoFollow      bool           `json:"nofollow,omitempty"`
+	SEO           SEO            `json:"seo"`
+	BrokenLinks   []BrokenLink   `json:"broken_links"`
+	BrokenAnchors []BrokenAnchor `json:"broken_anchors,omitempty"`
+	Assets        []Asset        `json:"assets"`
+	DiscoveredAt  string         `json:"discovered_at"`
 }
 
 // SEO describes title/description/h1 data for a page.
@@ -59,17 +275,52 @@ type SEO struct {
 }
 
 // BrokenLink describes an unreachable link (4xx/5xx or network error) with an absolute URL.
+// Redirect is set when the link redirected before failing.
 type BrokenLink struct {
+	URL        string    `json:"url"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty"`
+	Redirect   *Redirect `json:"redirect,omitempty"`
+}
+
+// BrokenAnchor is a link to a fragment that the target page does not define.
+// URL is the target page without the fragment; Fragment is the decoded fragment.
+type BrokenAnchor struct {
+	URL      string `json:"url"`
+	Fragment string `json:"fragment"`
+}
+
+// Redirect describes the redirect chain followed for a URL.
+// Hops lists each redirect response in order and FinalURL is where the chain ended.
+// Loop means the chain came back to a URL it had already visited and was stopped;
+// TooLong means it had more than Options.MaxRedirectHops hops or hit the client's limit.
+// External means a URL on the crawled site redirected outside it; such a page is not parsed.
+// Permanent means a URL on the crawled site redirects with 301 or 308, so links to it
+// should point to FinalURL instead.
+type Redirect struct {
+	Hops      []RedirectHop `json:"hops"`
+	FinalURL  string        `json:"final_url"`
+	Loop      bool          `json:"loop,omitempty"`
+	TooLong   bool          `json:"too_long,omitempty"`
+	External  bool          `json:"external,omitempty"`
+	Permanent bool          `json:"permanent,omitempty"`
+}
+
+// RedirectHop is one redirect response: the URL that was requested and its 3xx status.
+type RedirectHop struct {
 	URL        string `json:"url"`
 	StatusCode int    `json:"status_code"`
-	Error      string `json:"error,omitempty"`
 }
 
 // Asset describes a fetched asset; SizeBytes falls back to body length if Content-Length is missing.
+// Truncated means the body hit MaxAssetBytes, so a SizeBytes taken from the body is a lower bound.
+// Redirect is set when the asset URL redirected.
 type Asset struct {
-	URL        string `json:"url"`
-	Type       string `json:"type"`
-	StatusCode int    `json:"status_code"`
-	SizeBytes  int64  `json:"size_bytes"`
-	Error      string `json:"error,omitempty"`
+	URL        string    `json:"url"`
+	Type       string    `json:"type"`
+	StatusCode int       `json:"status_code"`
+	SizeBytes  int64     `json:"size_bytes"`
+	Error      string    `json:"error,omitempty"`
+	Truncated  bool      `json:"truncated,omitempty"`
+	Redirect   *Redirect `json:"redirect,omitempty"`
 }
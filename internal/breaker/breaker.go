@@ -0,0 +1,204 @@
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultCooldown = 30 * time.Second
+
+// ErrOpen is returned by Allow while a host's circuit is open.
+var ErrOpen = errors.New("circuit breaker open")
+
+// State is the state of one host's circuit.
+type State string
+
+// Circuit states.
+const (
+	Closed   State = "closed"
+	Open     State = "open"
+	HalfOpen State = "half_open"
+)
+
+// Clock provides the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// Config tunes the breaker. Threshold is the number of consecutive failures that opens
+// a circuit; Cooldown is how long it stays open before a single probe is let through.
+type Config struct {
+	Threshold int
+	Cooldown  time.Duration
+}
+
+// HostStats summarizes the circuit of one host.
+type HostStats struct {
+	Host     string
+	State    State
+	Trips    int
+	Rejected int
+}
+
+type circuit struct {
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+	trips    int
+	rejected int
+}
+
+// Registry keeps one circuit per host. A nil Registry allows every request.
+type Registry struct {
+	mu     sync.Mutex
+	config Config
+	clock  Clock
+	hosts  map[string]*circuit
+}
+
+// NewRegistry creates a registry, or returns nil when Threshold is not positive.
+func NewRegistry(config Config, clock Clock) *Registry {
+	if config.Threshold <= 0 {
+		return nil
+	}
+
+	if config.Cooldown <= 0 {
+		config.Cooldown = defaultCooldown
+	}
+
+	return &Registry{
+		config: config,
+		clock:  clock,
+		hosts:  map[string]*circuit{},
+	}
+}
+
+// Allow returns an error wrapping ErrOpen if requests to host must fail fast.
+// Once the cooldown has passed, one probe is allowed through in the half-open state.
+func (r *Registry) Allow(host string) error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.circuitLocked(host)
+	if c.state == Open && r.clock.Now().Sub(c.openedAt) >= r.config.Cooldown {
+		c.state = HalfOpen
+		c.probing = false
+	}
+
+	switch {
+	case c.state == Closed:
+		return nil
+	case c.state == HalfOpen && !c.probing:
+		c.probing = true
+
+		return nil
+	default:
+		c.rejected++
+
+		return fmt.Errorf("%w for %s after %d consecutive failures", ErrOpen, hostKey(host), r.config.Threshold)
+	}
+}
+
+// Success closes the host's circuit and resets its failure count.
+func (r *Registry) Success(host string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.circuitLocked(host)
+	c.state = Closed
+	c.failures = 0
+	c.probing = false
+}
+
+// Failure records a transport failure; a failed probe or the Threshold-th
+// consecutive failure opens the circuit.
+func (r *Registry) Failure(host string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.circuitLocked(host)
+	c.failures++
+
+	if c.state == HalfOpen || (c.state == Closed && c.failures >= r.config.Threshold) {
+		c.state = Open
+		c.openedAt = r.clock.Now()
+		c.probing = false
+		c.trips++
+	}
+}
+
+// Release gives up a request allowed by Allow without recording an outcome, such as one
+// canceled before it finished. A half-open circuit lets the next probe through.
+func (r *Registry) Release(host string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.circuitLocked(host).probing = false
+}
+
+// Stats returns hosts whose circuit opened at least once, sorted by host.
+func (r *Registry) Stats() []HostStats {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make([]HostStats, 0, len(r.hosts))
+	for host, c := range r.hosts {
+		if c.trips == 0 {
+			continue
+		}
+
+		stats = append(stats, HostStats{
+			Host:     host,
+			State:    c.state,
+			Trips:    c.trips,
+			Rejected: c.rejected,
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Host < stats[j].Host
+	})
+
+	return stats
+}
+
+func (r *Registry) circuitLocked(host string) *circuit {
+	key := hostKey(host)
+	if c, ok := r.hosts[key]; ok {
+		return c
+	}
+
+	c := &circuit{state: Closed}
+	r.hosts[key] = c
+
+	return c
+}
+
+func hostKey(host string) string {
+	return strings.ToLower(strings.TrimSpace(host))
+}
@@ -0,0 +1,142 @@
+package breaker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestNewRegistryDisabled(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry(Config{}, &fakeClock{})
+	if registry != nil {
+		t.Fatalf("expected nil registry for zero threshold")
+	}
+
+	if err := registry.Allow("example.com"); err != nil {
+		t.Fatalf("nil registry must allow requests, got %v", err)
+	}
+
+	registry.Failure("example.com")
+	registry.Success("example.com")
+	registry.Release("example.com")
+
+	if stats := registry.Stats(); stats != nil {
+		t.Fatalf("Stats() = %v; want nil", stats)
+	}
+}
+
+func TestRegistryOpensAfterThreshold(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry(Config{Threshold: 2, Cooldown: time.Minute}, &fakeClock{now: time.Unix(0, 0)})
+
+	registry.Failure("down.test")
+	if err := registry.Allow("down.test"); err != nil {
+		t.Fatalf("circuit opened before threshold: %v", err)
+	}
+
+	registry.Failure("DOWN.test")
+
+	err := registry.Allow("down.test")
+	if !errors.Is(err, ErrOpen) {
+		t.Fatalf("expected ErrOpen, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "down.test") {
+		t.Fatalf("error %q must name the host", err)
+	}
+
+	if err := registry.Allow("up.test"); err != nil {
+		t.Fatalf("other hosts must stay closed, got %v", err)
+	}
+}
+
+func TestRegistrySuccessResetsFailures(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry(Config{Threshold: 2}, &fakeClock{now: time.Unix(0, 0)})
+
+	registry.Failure("flaky.test")
+	registry.Success("flaky.test")
+	registry.Failure("flaky.test")
+
+	if err := registry.Allow("flaky.test"); err != nil {
+		t.Fatalf("failures must be consecutive, got %v", err)
+	}
+}
+
+func TestRegistryHalfOpenProbe(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	registry := NewRegistry(Config{Threshold: 1, Cooldown: time.Second}, clock)
+
+	registry.Failure("down.test")
+	clock.now = clock.now.Add(time.Second)
+
+	if err := registry.Allow("down.test"); err != nil {
+		t.Fatalf("probe must be allowed after cooldown, got %v", err)
+	}
+	if err := registry.Allow("down.test"); !errors.Is(err, ErrOpen) {
+		t.Fatalf("only one probe may run, got %v", err)
+	}
+
+	registry.Failure("down.test")
+	if err := registry.Allow("down.test"); !errors.Is(err, ErrOpen) {
+		t.Fatalf("failed probe must reopen the circuit, got %v", err)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	if err := registry.Allow("down.test"); err != nil {
+		t.Fatalf("second probe must be allowed, got %v", err)
+	}
+
+	registry.Success("down.test")
+	if err := registry.Allow("down.test"); err != nil {
+		t.Fatalf("successful probe must close the circuit, got %v", err)
+	}
+
+	stats := registry.Stats()
+	want := []HostStats{{Host: "down.test", State: Closed, Trips: 2, Rejected: 2}}
+	if len(stats) != 1 || stats[0] != want[0] {
+		t.Fatalf("Stats() = %+v; want %+v", stats, want)
+	}
+}
+
+func TestRegistryReleasedProbe(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	registry := NewRegistry(Config{Threshold: 1, Cooldown: time.Second}, clock)
+
+	registry.Failure("down.test")
+	clock.now = clock.now.Add(time.Second)
+
+	if err := registry.Allow("down.test"); err != nil {
+		t.Fatalf("probe must be allowed after cooldown, got %v", err)
+	}
+
+	registry.Release("down.test")
+	if err := registry.Allow("down.test"); err != nil {
+		t.Fatalf("released probe must let the next one through, got %v", err)
+	}
+	if err := registry.Allow("down.test"); !errors.Is(err, ErrOpen) {
+		t.Fatalf("only one probe may run, got %v", err)
+	}
+
+	stats := registry.Stats()
+	want := []HostStats{{Host: "down.test", State: HalfOpen, Trips: 1, Rejected: 1}}
+	if len(stats) != 1 || stats[0] != want[0] {
+		t.Fatalf("Stats() = %+v; want %+v", stats, want)
+	}
+}
@@ -0,0 +1,103 @@
+package fetcher
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"code/internal/mediatype"
+)
+
+// BodyLimits bounds how much of a response body is kept; zero fields mean no limit.
+// MaxBodyBytes applies to the bytes read from the connection, MaxDecompressedBytes
+// to the body after gzip decoding. HTMLOnly skips the body when Content-Type
+// declares a specific type that is not HTML or XHTML.
+type BodyLimits struct {
+	MaxBodyBytes         int64
+	MaxDecompressedBytes int64
+	HTMLOnly             bool
+}
+
+// WithLimits returns a Fetcher that shares everything with f, including the retry budget,
+// but keeps at most limits of each response body.
+func (f *Fetcher) WithLimits(limits BodyLimits) *Fetcher {
+	if f == nil {
+		return nil
+	}
+
+	clone := *f
+	clone.limits = limits
+
+	return &clone
+}
+
+// readBody reads the response body within the limits and reports whether it was cut short.
+// Gzip bodies the transport did not decode are decoded here so the decompressed cap applies.
+func (f *Fetcher) readBody(response *http.Response) ([]byte, bool, error) {
+	if response.Uncompressed {
+		return readLimited(response.Body, smallestLimit(f.limits.MaxBodyBytes, f.limits.MaxDecompressedBytes))
+	}
+
+	raw, truncated, err := readLimited(response.Body, f.limits.MaxBodyBytes)
+	if err != nil || !isGzip(response.Header) {
+		return raw, truncated, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return raw, truncated, nil
+	}
+
+	body, decodedTruncated, err := readLimited(reader, f.limits.MaxDecompressedBytes)
+	switch {
+	case err == nil:
+		return body, truncated || decodedTruncated, nil
+	case truncated:
+		// A cut-off gzip stream ends unexpectedly; keep what was decoded.
+		return body, true, nil
+	default:
+		return raw, false, nil
+	}
+}
+
+func (f *Fetcher) skipBody(header http.Header) bool {
+	if !f.limits.HTMLOnly {
+		return false
+	}
+
+	declared := mediatype.FromHeader(header.Get("Content-Type"))
+
+	return declared != "" && declared != "application/octet-stream" && !mediatype.IsHTML(declared)
+}
+
+func readLimited(reader io.Reader, limit int64) ([]byte, bool, error) {
+	if limit <= 0 {
+		body, err := io.ReadAll(reader)
+
+		return body, false, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if int64(len(body)) > limit {
+		return body[:limit], true, err
+	}
+
+	return body, false, err
+}
+
+func smallestLimit(a, b int64) int64 {
+	switch {
+	case a <= 0:
+		return b
+	case b <= 0:
+		return a
+	default:
+		return min(a, b)
+	}
+}
+
+func isGzip(header http.Header) bool {
+	return strings.EqualFold(strings.TrimSpace(header.Get("Content-Encoding")), "gzip")
+}
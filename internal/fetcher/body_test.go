@@ -0,0 +1,153 @@
+package fetcher
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchBodyLimits(t *testing.T) {
+	t.Parallel()
+
+	plain := strings.Repeat("a", 100)
+	compressed := gzipBody(t, strings.Repeat("b", 10000))
+
+	tests := []struct {
+		name          string
+		body          []byte
+		encoding      string
+		uncompressed  bool
+		limits        BodyLimits
+		wantBody      string
+		wantTruncated bool
+	}{
+		{name: "no limit", body: []byte(plain), wantBody: plain},
+		{name: "under limit", body: []byte(plain), limits: BodyLimits{MaxBodyBytes: 100}, wantBody: plain},
+		{
+			name:          "over limit",
+			body:          []byte(plain),
+			limits:        BodyLimits{MaxBodyBytes: 10},
+			wantBody:      plain[:10],
+			wantTruncated: true,
+		},
+		{
+			name:     "gzip decoded",
+			body:     compressed,
+			encoding: "gzip",
+			wantBody: strings.Repeat("b", 10000),
+		},
+		{
+			name:          "gzip bomb capped",
+			body:          compressed,
+			encoding:      "gzip",
+			limits:        BodyLimits{MaxDecompressedBytes: 50},
+			wantBody:      strings.Repeat("b", 50),
+			wantTruncated: true,
+		},
+		{
+			name:          "transport decoded uses smaller limit",
+			body:          []byte(plain),
+			uncompressed:  true,
+			limits:        BodyLimits{MaxBodyBytes: 50, MaxDecompressedBytes: 20},
+			wantBody:      plain[:20],
+			wantTruncated: true,
+		},
+		{
+			name:     "invalid gzip kept raw",
+			body:     []byte(plain),
+			encoding: "gzip",
+			wantBody: plain,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				response := &http.Response{
+					StatusCode:   http.StatusOK,
+					Body:         io.NopCloser(bytes.NewReader(tt.body)),
+					Header:       http.Header{},
+					Uncompressed: tt.uncompressed,
+				}
+				if tt.encoding != "" {
+					response.Header.Set("Content-Encoding", tt.encoding)
+				}
+				return response, nil
+			})
+
+			fetch := New(&http.Client{Transport: rt}, time.Second, "", nil, nil, RetryPolicy{}, testClock{}).
+				WithLimits(tt.limits)
+
+			result, err := fetch.Fetch(context.Background(), exampleURL)
+			if err != nil {
+				t.Fatalf("Fetch returned error: %v", err)
+			}
+			if string(result.Body) != tt.wantBody {
+				t.Fatalf("body length = %d; want %d", len(result.Body), len(tt.wantBody))
+			}
+			if result.Truncated != tt.wantTruncated {
+				t.Fatalf("truncated = %v; want %v", result.Truncated, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func gzipBody(t *testing.T, data string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(data)); err != nil {
+		t.Fatalf("gzip write: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestFetchHTMLOnlySkipsOtherBodies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		contentType string
+		wantSkipped bool
+	}{
+		{contentType: "application/pdf", wantSkipped: true},
+		{contentType: "text/html; charset=utf-8", wantSkipped: false},
+		{contentType: "application/octet-stream", wantSkipped: false},
+		{contentType: "", wantSkipped: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			t.Parallel()
+
+			rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				response := newResponse(http.StatusOK, "body")
+				response.Header.Set("Content-Type", tt.contentType)
+				return response, nil
+			})
+
+			fetch := New(&http.Client{Transport: rt}, time.Second, "", nil, nil, RetryPolicy{}, testClock{}).
+				WithLimits(BodyLimits{HTMLOnly: true})
+
+			result, err := fetch.Fetch(context.Background(), exampleURL)
+			if err != nil {
+				t.Fatalf("Fetch returned error: %v", err)
+			}
+			if result.BodySkipped != tt.wantSkipped || (len(result.Body) == 0) != tt.wantSkipped {
+				t.Fatalf("skipped = %v, body = %q; want skipped %v", result.BodySkipped, result.Body, tt.wantSkipped)
+			}
+		})
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 	"time"
 
+	"code/internal/breaker"
 	"code/internal/limiter"
 )
 
@@ -21,57 +24,94 @@ const (
 var errInvalidRequest = errors.New("invalid request")
 
 // Result contains the HTTP response data.
+// Truncated is set when Body was cut short by the fetcher's BodyLimits;
+// BodySkipped when the body was not read because it is not HTML (BodyLimits.HTMLOnly).
+// Redirects lists the redirect responses that were followed, in order, and FinalURL is the
+// URL that produced the result; both are set even when a redirect loop stopped the request.
 type Result struct {
-	StatusCode int
-	Header     http.Header
-	Body       []byte
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+	Truncated   bool
+	BodySkipped bool
+	Redirects   []Hop
+	FinalURL    string
 }
 
-// Fetcher performs HTTP requests with retries and rate limiting.
+// Fetcher performs HTTP requests with retries and per-host rate limiting.
 type Fetcher struct {
-	client     *http.Client
-	timeout    time.Duration
-	userAgent  string
-	limiter    *limiter.Limiter
-	retries    int
-	retryDelay time.Duration
-	clock      limiter.Timer
+	client      *http.Client
+	timeout     time.Duration
+	userAgent   string
+	limiters    *limiter.Registry
+	breakers    *breaker.Registry
+	policy      RetryPolicy
+	clock       limiter.Timer
+	random      func() float64
+	retriesUsed *atomic.Int64
+	limits      BodyLimits
 }
 
 // New creates a Fetcher with the provided configuration.
+// Zero delays in policy fall back to the defaults (100ms base, 2s max, 30s Retry-After cap).
+// A nil breakers registry disables circuit breaking.
 func New(
 	client *http.Client,
 	timeout time.Duration,
 	userAgent string,
-	limiter *limiter.Limiter,
-	retries int,
-	retryDelay time.Duration,
+	limiters *limiter.Registry,
+	breakers *breaker.Registry,
+	policy RetryPolicy,
 	clock limiter.Timer,
 ) *Fetcher {
-	if retryDelay <= 0 {
-		retryDelay = baseRetryDelay
+	return &Fetcher{
+		client:      recordRedirects(client),
+		timeout:     timeout,
+		userAgent:   userAgent,
+		limiters:    limiters,
+		breakers:    breakers,
+		policy:      policy.withDefaults(),
+		clock:       clock,
+		random:      rand.Float64,
+		retriesUsed: &atomic.Int64{},
 	}
+}
 
-	return &Fetcher{
-		client:     client,
-		timeout:    timeout,
-		userAgent:  userAgent,
-		limiter:    limiter,
-		retries:    retries,
-		retryDelay: retryDelay,
-		clock:      clock,
+// WithTimeout returns a Fetcher that shares everything with f, including the retry budget,
+// but gives each request timeout instead; zero means no timeout of its own.
+func (f *Fetcher) WithTimeout(timeout time.Duration) *Fetcher {
+	if f == nil {
+		return nil
 	}
+
+	clone := *f
+	clone.timeout = timeout
+
+	return &clone
 }
 
-// Fetch performs a GET request with retries for temporary failures (network errors, 429, 5xx).
+// Fetch performs a GET request with retries for temporary failures
+// (by default network errors, 429 and 5xx; see RetryPolicy).
+// While the host's circuit breaker is open it fails fast with an error wrapping breaker.ErrOpen.
+// A Retry-After header also pauses the host in the limiter registry so other workers wait too.
 // It returns the result from the last attempt.
 func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (Result, error) {
-	attempts := f.retries + 1
+	return f.fetch(ctx, http.MethodGet, rawURL)
+}
+
+// Head performs a HEAD request with the same retries, rate limiting and circuit breaking as Fetch.
+// The result has no body.
+func (f *Fetcher) Head(ctx context.Context, rawURL string) (Result, error) {
+	return f.fetch(ctx, http.MethodHead, rawURL)
+}
+
+func (f *Fetcher) fetch(ctx context.Context, method string, rawURL string) (Result, error) {
+	attempts := f.policy.Retries + 1
 	var lastResult Result
 	var lastErr error
 
 	for attempt := range attempts {
-		result, err := f.fetchOnce(ctx, rawURL)
+		result, err := f.fetchOnce(ctx, method, rawURL)
 		lastResult = result
 		lastErr = err
 
@@ -83,7 +123,7 @@ func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (Result, error) {
 			return result, nil
 		}
 
-		retry, retryErr := f.shouldRetry(ctx, attempt, attempts, result, err)
+		retry, retryErr := f.shouldRetry(ctx, rawURL, attempt, attempts, result, err)
 		if !retry {
 			return result, retryErr
 		}
@@ -92,18 +132,55 @@ func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (Result, error) {
 	return lastResult, lastErr
 }
 
-func (f *Fetcher) fetchOnce(ctx context.Context, rawURL string) (Result, error) {
-	if f.limiter != nil {
-		if err := f.limiter.Wait(ctx); err != nil {
-			return Result{}, err
-		}
+func (f *Fetcher) fetchOnce(ctx context.Context, method string, rawURL string) (Result, error) {
+	host := hostOf(rawURL)
+	if err := f.breakers.Allow(host); err != nil {
+		return Result{}, err
 	}
 
-	return f.doRequest(ctx, rawURL)
+	if err := f.limiters.Wait(ctx, host); err != nil {
+		f.breakers.Release(host)
+
+		return Result{}, err
+	}
+
+	start := f.clock.Now()
+	result, err := f.doRequest(ctx, method, rawURL)
+	if result.StatusCode != 0 {
+		f.limiters.Observe(host, result.StatusCode, f.clock.Now().Sub(start))
+	}
+
+	f.recordCircuit(ctx, host, err)
+
+	return result, err
+}
+
+// recordCircuit feeds the outcome of a request into the host's circuit breaker.
+// Only transport failures count; HTTP error statuses mean the host is reachable.
+// Canceled and invalid requests release their slot without an outcome.
+func (f *Fetcher) recordCircuit(ctx context.Context, host string, err error) {
+	switch {
+	case ctx.Err() != nil || errors.Is(err, errInvalidRequest):
+		f.breakers.Release(host)
+	case err != nil && !isRedirectError(err):
+		f.breakers.Failure(host)
+	default:
+		f.breakers.Success(host)
+	}
+}
+
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Host
 }
 
 func (f *Fetcher) shouldRetry(
 	ctx context.Context,
+	rawURL string,
 	attempt int,
 	attempts int,
 	result Result,
@@ -113,11 +190,20 @@ func (f *Fetcher) shouldRetry(
 		return false, coalesceError(err, ctx.Err())
 	}
 
-	if !isRetryable(result.StatusCode, err) || attempt == attempts-1 {
+	if !f.isRetryable(result.StatusCode, err) {
 		return false, errorForStatus(err, result.StatusCode)
 	}
 
-	sleepDelay := f.retryDelayFor(attempt + 1)
+	sleepDelay, fromServer := f.retryAfter(result)
+	if fromServer {
+		f.limiters.Pause(hostOf(rawURL), sleepDelay)
+	} else {
+		sleepDelay = f.retryDelayFor(attempt + 1)
+	}
+
+	if attempt == attempts-1 || !f.takeRetry() {
+		return false, errorForStatus(err, result.StatusCode)
+	}
 
 	err = f.clock.Sleep(ctx, sleepDelay)
 	if err != nil {
@@ -127,7 +213,7 @@ func (f *Fetcher) shouldRetry(
 	return true, nil
 }
 
-func (f *Fetcher) doRequest(ctx context.Context, rawURL string) (Result, error) {
+func (f *Fetcher) doRequest(ctx context.Context, method string, rawURL string) (Result, error) {
 	requestCtx := ctx
 	if f.timeout > 0 {
 		var cancel context.CancelFunc
@@ -140,7 +226,9 @@ func (f *Fetcher) doRequest(ctx context.Context, rawURL string) (Result, error)
 		return Result{}, fmt.Errorf("%w: %v", errInvalidRequest, err)
 	}
 
-	request, err := http.NewRequestWithContext(requestCtx, http.MethodGet, parsedURL.String(), nil)
+	requestCtx, redirects := withRedirectRecorder(requestCtx, parsedURL.String())
+
+	request, err := http.NewRequestWithContext(requestCtx, method, parsedURL.String(), nil)
 	if err != nil {
 		return Result{}, fmt.Errorf("%w: %v", errInvalidRequest, err)
 	}
@@ -151,18 +239,37 @@ func (f *Fetcher) doRequest(ctx context.Context, rawURL string) (Result, error)
 
 	response, err := f.client.Do(request)
 	if err != nil {
+		if isRedirectError(err) {
+			return Result{Redirects: redirects.hops, FinalURL: redirects.finalURL}, err
+		}
+
 		return Result{}, err
 	}
 	defer func() {
 		_ = response.Body.Close()
 	}()
 
-	body, err := io.ReadAll(response.Body)
+	result := Result{
+		StatusCode: response.StatusCode,
+		Header:     response.Header,
+		Redirects:  redirects.hops,
+		FinalURL:   redirects.finalURL,
+	}
+
+	if f.skipBody(response.Header) {
+		result.BodySkipped = true
+
+		return result, nil
+	}
+
+	result.Body, result.Truncated, err = f.readBody(response)
 	if err != nil {
-		return Result{StatusCode: response.StatusCode, Header: response.Header}, fmt.Errorf("read body: %w", err)
+		result.Body, result.Truncated = nil, false
+
+		return result, fmt.Errorf("read body: %w", err)
 	}
 
-	return Result{StatusCode: response.StatusCode, Header: response.Header, Body: body}, nil
+	return result, nil
 }
 
 func isRetryable(statusCode int, err error) bool {
@@ -285,24 +392,3 @@ func coalesceError(primary, fallback error) error {
 
 	return fallback
 }
-
-func (f *Fetcher) retryDelayFor(attempt int) time.Duration {
-	if attempt < 1 {
-		attempt = 1
-	}
-
-	delay := f.retryDelay
-	for i := 1; i < attempt; i++ {
-		if delay >= maxRetryDelay {
-			return maxRetryDelay
-		}
-
-		delay *= 2
-	}
-
-	if delay > maxRetryDelay {
-		return maxRetryDelay
-	}
-
-	return delay
-}
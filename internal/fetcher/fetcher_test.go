@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"code/internal/breaker"
 )
 
 const exampleURL = "https://example.com/"
@@ -47,7 +49,7 @@ func newTestFetcher(
 	retries int,
 	sleepFn func(context.Context, time.Duration) error,
 ) *Fetcher {
-	return New(client, time.Second, "", nil, retries, baseRetryDelay, testClock{sleepFn: sleepFn})
+	return New(client, time.Second, "", nil, nil, RetryPolicy{Retries: retries}, testClock{sleepFn: sleepFn})
 }
 
 func TestFetchOK(t *testing.T) {
@@ -430,3 +432,127 @@ func (retryableNetError) Error() string { return "temporary network error" }
 func (retryableNetError) Timeout() bool { return false }
 
 func (retryableNetError) Temporary() bool { return true }
+
+func TestFetchCircuitBreakerCountsOnlyTransportFailures(t *testing.T) {
+	t.Parallel()
+
+	breakers := breaker.NewRegistry(breaker.Config{Threshold: 1}, testClock{})
+
+	status := newResponse(http.StatusInternalServerError, "")
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if status != nil {
+			response := status
+			status = nil
+			return response, nil
+		}
+		return nil, io.ErrUnexpectedEOF
+	})
+
+	fetch := New(&http.Client{Transport: rt}, time.Second, "", nil, breakers, RetryPolicy{}, testClock{})
+
+	if _, err := fetch.Fetch(context.Background(), exampleURL); errors.Is(err, breaker.ErrOpen) {
+		t.Fatalf("HTTP error status must not open the circuit")
+	}
+	if _, err := fetch.Fetch(context.Background(), exampleURL); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected transport error, got %v", err)
+	}
+	if _, err := fetch.Fetch(context.Background(), exampleURL); !errors.Is(err, breaker.ErrOpen) {
+		t.Fatalf("expected open circuit, got %v", err)
+	}
+}
+
+type breakerClock struct {
+	now time.Time
+}
+
+func (c *breakerClock) Now() time.Time {
+	return c.now
+}
+
+func TestFetchCanceledProbeReleasesCircuit(t *testing.T) {
+	t.Parallel()
+
+	clock := &breakerClock{now: time.Unix(0, 0)}
+	breakers := breaker.NewRegistry(breaker.Config{Threshold: 1, Cooldown: time.Second}, clock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		switch calls {
+		case 1:
+			return nil, io.ErrUnexpectedEOF
+		case 2:
+			cancel()
+			return nil, req.Context().Err()
+		default:
+			return newResponse(http.StatusOK, "ok"), nil
+		}
+	})
+
+	fetch := New(&http.Client{Transport: rt}, time.Second, "", nil, breakers, RetryPolicy{}, testClock{})
+
+	if _, err := fetch.Fetch(context.Background(), exampleURL); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected transport error, got %v", err)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	if _, err := fetch.Fetch(ctx, exampleURL); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled probe, got %v", err)
+	}
+
+	result, err := fetch.Fetch(context.Background(), exampleURL)
+	if err != nil {
+		t.Fatalf("canceled probe must not keep the circuit open, got %v", err)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Fatalf("StatusCode = %d; want %d", result.StatusCode, http.StatusOK)
+	}
+}
+
+func TestHeadUsesHeadMethod(t *testing.T) {
+	t.Parallel()
+
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodHead {
+			t.Fatalf("method = %q; want %q", req.Method, http.MethodHead)
+		}
+
+		response := newResponse(http.StatusOK, "")
+		response.Header.Set("Content-Length", "42")
+		return response, nil
+	})
+
+	fetch := newTestFetcher(&http.Client{Transport: rt}, 0, nil)
+
+	result, err := fetch.Head(context.Background(), exampleURL)
+	if err != nil {
+		t.Fatalf("Head returned error: %v", err)
+	}
+	if result.Header.Get("Content-Length") != "42" {
+		t.Fatalf("Content-Length = %q; want %q", result.Header.Get("Content-Length"), "42")
+	}
+}
+
+func TestWithTimeoutAppliesToRequests(t *testing.T) {
+	t.Parallel()
+
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		deadline, ok := req.Context().Deadline()
+		if !ok {
+			t.Fatal("request has no deadline")
+		}
+		if remaining := time.Until(deadline); remaining > time.Minute {
+			t.Fatalf("deadline in %s; want at most a minute", remaining)
+		}
+
+		return newResponse(http.StatusOK, ""), nil
+	})
+
+	fetch := New(&http.Client{Transport: rt}, time.Hour, "", nil, nil, RetryPolicy{}, testClock{}).
+		WithTimeout(time.Minute)
+
+	if _, err := fetch.Fetch(context.Background(), exampleURL); err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+}
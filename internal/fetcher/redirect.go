@@ -0,0 +1,96 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const maxRedirects = 10
+
+var (
+	// ErrRedirectLoop is returned when a redirect points back to a URL already visited.
+	ErrRedirectLoop = errors.New("redirect loop")
+	// ErrTooManyRedirects is returned when a request is redirected more than 10 times.
+	ErrTooManyRedirects = errors.New("too many redirects")
+)
+
+// Hop is one redirect response on the way to the final URL.
+type Hop struct {
+	URL        string
+	StatusCode int
+}
+
+type redirectKey struct{}
+
+// redirectRecorder collects the hops of one request.
+type redirectRecorder struct {
+	hops     []Hop
+	finalURL string
+}
+
+// recordRedirects returns a copy of client that records the redirects it follows for
+// requests made with a recorder in their context. A CheckRedirect set on client still decides whether
+// to follow a redirect; without one, loops and chains over 10 hops are stopped.
+func recordRedirects(client *http.Client) *http.Client {
+	if client == nil {
+		return nil
+	}
+
+	next := client.CheckRedirect
+	clone := *client
+	clone.CheckRedirect = func(request *http.Request, via []*http.Request) error {
+		err := checkRedirect(next, request, via)
+		if err != nil && !isRedirectError(err) {
+			return err
+		}
+
+		if recorder, ok := request.Context().Value(redirectKey{}).(*redirectRecorder); ok {
+			previous := via[len(via)-1]
+			recorder.hops = append(recorder.hops, Hop{URL: previous.URL.String(), StatusCode: redirectStatus(request)})
+			recorder.finalURL = request.URL.String()
+		}
+
+		return err
+	}
+
+	return &clone
+}
+
+// checkRedirect stops loops, then defers to the client's own policy or the default limit.
+func checkRedirect(next func(*http.Request, []*http.Request) error, request *http.Request, via []*http.Request) error {
+	for _, visited := range via {
+		if visited.URL.String() == request.URL.String() {
+			return fmt.Errorf("%w: %s", ErrRedirectLoop, request.URL)
+		}
+	}
+
+	if next != nil {
+		return next(request, via)
+	}
+
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("%w: stopped after %d", ErrTooManyRedirects, maxRedirects)
+	}
+
+	return nil
+}
+
+func withRedirectRecorder(ctx context.Context, rawURL string) (context.Context, *redirectRecorder) {
+	recorder := &redirectRecorder{finalURL: rawURL}
+
+	return context.WithValue(ctx, redirectKey{}, recorder), recorder
+}
+
+func redirectStatus(request *http.Request) int {
+	if request.Response == nil {
+		return 0
+	}
+
+	return request.Response.StatusCode
+}
+
+func isRedirectError(err error) bool {
+	return errors.Is(err, ErrRedirectLoop) || errors.Is(err, ErrTooManyRedirects)
+}
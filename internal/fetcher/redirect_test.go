@@ -0,0 +1,147 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"code/internal/breaker"
+)
+
+func redirectTo(status int, location string) *http.Response {
+	response := newResponse(status, "")
+	response.Header.Set("Location", location)
+
+	return response
+}
+
+func TestFetchRecordsRedirects(t *testing.T) {
+	t.Parallel()
+
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/old":
+			return redirectTo(http.StatusMovedPermanently, "/temp"), nil
+		case "/temp":
+			return redirectTo(http.StatusFound, "https://www.example.com/new"), nil
+		default:
+			return newResponse(http.StatusOK, "ok"), nil
+		}
+	})
+
+	fetch := newTestFetcher(&http.Client{Transport: rt}, 0, nil)
+
+	result, err := fetch.Fetch(context.Background(), "https://example.com/old")
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+
+	want := []Hop{
+		{URL: "https://example.com/old", StatusCode: http.StatusMovedPermanently},
+		{URL: "https://example.com/temp", StatusCode: http.StatusFound},
+	}
+	if !reflect.DeepEqual(result.Redirects, want) {
+		t.Fatalf("Redirects = %+v; want %+v", result.Redirects, want)
+	}
+	if result.FinalURL != "https://www.example.com/new" {
+		t.Fatalf("FinalURL = %q", result.FinalURL)
+	}
+	if result.StatusCode != http.StatusOK || string(result.Body) != "ok" {
+		t.Fatalf("result = %d %q; want the final response", result.StatusCode, result.Body)
+	}
+}
+
+func TestFetchWithoutRedirectKeepsURL(t *testing.T) {
+	t.Parallel()
+
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, "ok"), nil
+	})
+
+	result, err := newTestFetcher(&http.Client{Transport: rt}, 0, nil).Fetch(context.Background(), exampleURL)
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	if len(result.Redirects) != 0 || result.FinalURL != exampleURL {
+		t.Fatalf("result = %+v, %q; want no redirects", result.Redirects, result.FinalURL)
+	}
+}
+
+func TestFetchStopsRedirectLoop(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if req.URL.Path == "/a" {
+			return redirectTo(http.StatusFound, "/b"), nil
+		}
+		return redirectTo(http.StatusFound, "/a"), nil
+	})
+
+	breakers := breaker.NewRegistry(breaker.Config{Threshold: 1}, testClock{})
+	fetch := New(&http.Client{Transport: rt}, time.Second, "", nil, breakers, RetryPolicy{Retries: 2}, testClock{})
+
+	result, err := fetch.Fetch(context.Background(), "https://example.com/a")
+	if !errors.Is(err, ErrRedirectLoop) {
+		t.Fatalf("expected ErrRedirectLoop, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d; want 2 without retries", calls)
+	}
+	if len(result.Redirects) != 2 || result.FinalURL != "https://example.com/a" {
+		t.Fatalf("result = %+v, %q; want both hops back to /a", result.Redirects, result.FinalURL)
+	}
+
+	if _, err := fetch.Fetch(context.Background(), "https://example.com/a"); errors.Is(err, breaker.ErrOpen) {
+		t.Fatal("a redirect loop must not open the circuit")
+	}
+}
+
+func TestFetchStopsLongRedirectChains(t *testing.T) {
+	t.Parallel()
+
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return redirectTo(http.StatusFound, "/next"+req.URL.Path), nil
+	})
+
+	result, err := newTestFetcher(&http.Client{Transport: rt}, 0, nil).Fetch(context.Background(), exampleURL)
+	if !errors.Is(err, ErrTooManyRedirects) {
+		t.Fatalf("expected ErrTooManyRedirects, got %v", err)
+	}
+	if len(result.Redirects) != maxRedirects {
+		t.Fatalf("len(Redirects) = %d; want %d", len(result.Redirects), maxRedirects)
+	}
+}
+
+func TestFetchKeepsClientCheckRedirect(t *testing.T) {
+	t.Parallel()
+
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/old" {
+			return redirectTo(http.StatusMovedPermanently, "/new"), nil
+		}
+		return newResponse(http.StatusOK, "ok"), nil
+	})
+
+	client := &http.Client{
+		Transport: rt,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	result, err := newTestFetcher(client, 0, nil).Fetch(context.Background(), "https://example.com/old")
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	if result.StatusCode != http.StatusMovedPermanently {
+		t.Fatalf("StatusCode = %d; want the redirect itself", result.StatusCode)
+	}
+	if len(result.Redirects) != 0 || result.FinalURL != "https://example.com/old" {
+		t.Fatalf("result = %+v, %q; want no followed redirects", result.Redirects, result.FinalURL)
+	}
+}
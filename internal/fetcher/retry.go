@@ -0,0 +1,221 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultMaxRetryAfter = 30 * time.Second
+	defaultMultiplier    = 2
+)
+
+// ErrorClass groups transport errors for RetryPolicy.ErrorClasses.
+type ErrorClass string
+
+// Error classes understood by RetryPolicy.
+const (
+	ErrorDNS               ErrorClass = "dns"
+	ErrorConnectionRefused ErrorClass = "connection_refused"
+	ErrorTimeout           ErrorClass = "timeout"
+	ErrorTLS               ErrorClass = "tls"
+)
+
+// ParseErrorClass validates an error class name.
+func ParseErrorClass(value string) (ErrorClass, error) {
+	class := ErrorClass(strings.ToLower(strings.TrimSpace(value)))
+	switch class {
+	case ErrorDNS, ErrorConnectionRefused, ErrorTimeout, ErrorTLS:
+		return class, nil
+	default:
+		return "", fmt.Errorf("unknown error class %q", value)
+	}
+}
+
+// RetryPolicy controls how Fetch retries temporary failures.
+// Retries is the number of retries after the first attempt.
+// StatusCodes lists the retryable HTTP statuses; empty means 429 and every 5xx.
+// ErrorClasses lists the retryable transport errors; empty means any network error.
+// BaseDelay grows by Multiplier (default 2) on every retry up to MaxDelay.
+// A Retry-After header on 429/503 replaces the backoff, capped at MaxRetryAfter.
+// Jitter in [0, 1] shortens each backoff delay by a random fraction of up to Jitter.
+// Budget > 0 caps the retries made by one Fetcher over its lifetime.
+type RetryPolicy struct {
+	Retries       int
+	StatusCodes   []int
+	ErrorClasses  []ErrorClass
+	BaseDelay     time.Duration
+	MaxDelay      time.Duration
+	Multiplier    float64
+	MaxRetryAfter time.Duration
+	Jitter        float64
+	Budget        int
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = baseRetryDelay
+	}
+
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = maxRetryDelay
+	}
+
+	if p.Multiplier <= 0 {
+		p.Multiplier = defaultMultiplier
+	}
+
+	p.Multiplier = max(p.Multiplier, 1)
+
+	if p.MaxRetryAfter <= 0 {
+		p.MaxRetryAfter = defaultMaxRetryAfter
+	}
+
+	p.Jitter = min(max(p.Jitter, 0), 1)
+
+	return p
+}
+
+func (f *Fetcher) isRetryable(statusCode int, err error) bool {
+	if err != nil {
+		if len(f.policy.ErrorClasses) == 0 {
+			return isRetryableError(err)
+		}
+
+		class, ok := classifyError(err)
+
+		return ok && slices.Contains(f.policy.ErrorClasses, class)
+	}
+
+	if len(f.policy.StatusCodes) == 0 {
+		return isRetryable(statusCode, nil)
+	}
+
+	return slices.Contains(f.policy.StatusCodes, statusCode)
+}
+
+// takeRetry spends one retry from the budget; it always succeeds without a budget.
+func (f *Fetcher) takeRetry() bool {
+	if f.policy.Budget <= 0 {
+		return true
+	}
+
+	return f.retriesUsed.Add(1) <= int64(f.policy.Budget)
+}
+
+func classifyError(err error) (ErrorClass, bool) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, errInvalidRequest) {
+		return "", false
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrorDNS, true
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorConnectionRefused, true
+	}
+
+	if isTimeout(err) {
+		return ErrorTimeout, true
+	}
+
+	if isTLSError(err) {
+		return ErrorTLS, true
+	}
+
+	return "", false
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func isTLSError(err error) bool {
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var verifyErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certErr x509.CertificateInvalidError
+
+	return errors.As(err, &recordErr) ||
+		errors.As(err, &alertErr) ||
+		errors.As(err, &verifyErr) ||
+		errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &certErr)
+}
+
+// retryAfter returns the server-requested wait for a 429 or 503 response, capped at MaxRetryAfter.
+func (f *Fetcher) retryAfter(result Result) (time.Duration, bool) {
+	if result.StatusCode != http.StatusTooManyRequests && result.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	wait, ok := parseRetryAfter(result.Header.Get("Retry-After"), f.clock.Now())
+	if !ok {
+		return 0, false
+	}
+
+	return min(wait, f.policy.MaxRetryAfter), true
+}
+
+// parseRetryAfter accepts both forms from RFC 9110: delay-seconds and an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(at.Sub(now), 0), true
+}
+
+func (f *Fetcher) retryDelayFor(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := f.policy.BaseDelay
+	for i := 1; i < attempt && delay < f.policy.MaxDelay; i++ {
+		delay = time.Duration(float64(delay) * f.policy.Multiplier)
+	}
+
+	delay = min(delay, f.policy.MaxDelay)
+
+	if f.policy.Jitter > 0 {
+		delay -= time.Duration(float64(delay) * f.policy.Jitter * f.random())
+	}
+
+	return delay
+}
@@ -0,0 +1,304 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"code/internal/limiter"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "seconds", value: "3", want: 3 * time.Second, wantOK: true},
+		{name: "http date", value: now.Add(5 * time.Second).UTC().Format(http.TimeFormat), want: 5 * time.Second, wantOK: true},
+		{name: "date in the past", value: now.Add(-time.Minute).UTC().Format(http.TimeFormat), want: 0, wantOK: true},
+		{name: "empty", value: "", wantOK: false},
+		{name: "negative", value: "-1", wantOK: false},
+		{name: "garbage", value: "soon", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := parseRetryAfter(tt.value, now)
+			if ok != tt.wantOK || got != tt.want {
+				t.Fatalf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFetchHonorsRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		status int
+		header string
+		policy RetryPolicy
+		want   time.Duration
+	}{
+		{name: "429 seconds", status: http.StatusTooManyRequests, header: "2", want: 2 * time.Second},
+		{name: "503 capped", status: http.StatusServiceUnavailable, header: "120", want: defaultMaxRetryAfter},
+		{
+			name:   "custom cap",
+			status: http.StatusTooManyRequests,
+			header: "10",
+			policy: RetryPolicy{MaxRetryAfter: time.Second},
+			want:   time.Second,
+		},
+		{name: "500 ignores header", status: http.StatusInternalServerError, header: "10", want: baseRetryDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var sleeps []time.Duration
+			sleepFn := func(_ context.Context, d time.Duration) error {
+				sleeps = append(sleeps, d)
+				return nil
+			}
+
+			calls := 0
+			rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				if calls == 1 {
+					response := newResponse(tt.status, "")
+					response.Header.Set("Retry-After", tt.header)
+					return response, nil
+				}
+				return newResponse(http.StatusOK, "ok"), nil
+			})
+
+			policy := tt.policy
+			policy.Retries = 1
+			fetch := New(&http.Client{Transport: rt}, time.Second, "", nil, nil, policy, testClock{sleepFn: sleepFn})
+
+			if _, err := fetch.Fetch(context.Background(), exampleURL); err != nil {
+				t.Fatalf("Fetch returned error: %v", err)
+			}
+			if len(sleeps) != 1 || sleeps[0] != tt.want {
+				t.Fatalf("sleeps = %v; want [%v]", sleeps, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchRetryAfterPausesHost(t *testing.T) {
+	t.Parallel()
+
+	var sleeps []time.Duration
+	clock := testClock{sleepFn: func(_ context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}}
+	registry := limiter.NewRegistry(nil, 0, 0, nil, clock)
+
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		response := newResponse(http.StatusTooManyRequests, "")
+		response.Header.Set("Retry-After", "4")
+		return response, nil
+	})
+
+	fetch := New(&http.Client{Transport: rt}, time.Second, "", registry, nil, RetryPolicy{}, clock)
+	if _, err := fetch.Fetch(context.Background(), exampleURL); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if len(sleeps) != 0 {
+		t.Fatalf("sleeps = %v; want none without retries", sleeps)
+	}
+
+	if err := registry.Wait(context.Background(), "example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sleeps) != 1 || sleeps[0] != 4*time.Second {
+		t.Fatalf("sleeps = %v; want the next request to the host to wait 4s", sleeps)
+	}
+}
+
+func TestRetryDelayForJitter(t *testing.T) {
+	t.Parallel()
+
+	fetch := New(nil, 0, "", nil, nil, RetryPolicy{Jitter: 0.5}, testClock{})
+	fetch.random = func() float64 { return 1 }
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 50 * time.Millisecond},
+		{attempt: 2, want: 100 * time.Millisecond},
+		{attempt: 10, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := fetch.retryDelayFor(tt.attempt); got != tt.want {
+			t.Fatalf("retryDelayFor(%d) = %v; want %v", tt.attempt, got, tt.want)
+		}
+	}
+
+	fetch.random = func() float64 { return 0 }
+	if got := fetch.retryDelayFor(1); got != baseRetryDelay {
+		t.Fatalf("retryDelayFor(1) without jitter = %v; want %v", got, baseRetryDelay)
+	}
+}
+
+func TestRetryDelayForMultiplier(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second, Multiplier: 3}
+	fetch := New(nil, 0, "", nil, nil, policy, testClock{})
+
+	want := []time.Duration{time.Second, 3 * time.Second, 9 * time.Second, 10 * time.Second}
+	for i, wantDelay := range want {
+		if got := fetch.retryDelayFor(i + 1); got != wantDelay {
+			t.Fatalf("retryDelayFor(%d) = %v; want %v", i+1, got, wantDelay)
+		}
+	}
+}
+
+func TestFetchRetryPolicyStatusCodes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		status    int
+		wantCalls int
+	}{
+		{name: "listed status", status: http.StatusBadGateway, wantCalls: 3},
+		{name: "default 5xx not listed", status: http.StatusInternalServerError, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				return newResponse(tt.status, ""), nil
+			})
+
+			policy := RetryPolicy{Retries: 2, StatusCodes: []int{http.StatusBadGateway}}
+			fetch := New(&http.Client{Transport: rt}, time.Second, "", nil, nil, policy, testClock{})
+
+			if _, err := fetch.Fetch(context.Background(), exampleURL); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if calls != tt.wantCalls {
+				t.Fatalf("calls = %d; want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	t.Parallel()
+
+	wrap := func(err error) error {
+		return &url.Error{Op: "Get", URL: exampleURL, Err: err}
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		want   ErrorClass
+		wantOK bool
+	}{
+		{name: "dns", err: wrap(&net.DNSError{Err: "no such host", Name: "example.com"}), want: ErrorDNS, wantOK: true},
+		{
+			name:   "connection refused",
+			err:    wrap(&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}),
+			want:   ErrorConnectionRefused,
+			wantOK: true,
+		},
+		{name: "deadline", err: wrap(context.DeadlineExceeded), want: ErrorTimeout, wantOK: true},
+		{name: "tls", err: wrap(x509.UnknownAuthorityError{}), want: ErrorTLS, wantOK: true},
+		{name: "canceled", err: wrap(context.Canceled), wantOK: false},
+		{name: "other", err: errors.New("boom"), wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := classifyError(tt.err)
+			if ok != tt.wantOK || got != tt.want {
+				t.Fatalf("classifyError() = %q, %v; want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFetchRetryPolicyErrorClasses(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return nil, &net.DNSError{Err: "no such host", Name: "example.com"}
+	})
+
+	policy := RetryPolicy{Retries: 2, ErrorClasses: []ErrorClass{ErrorTimeout}}
+	fetch := New(&http.Client{Transport: rt}, time.Second, "", nil, nil, policy, testClock{})
+
+	if _, err := fetch.Fetch(context.Background(), exampleURL); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d; want 1 for an error class outside the policy", calls)
+	}
+}
+
+func TestFetchRetryBudget(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return newResponse(http.StatusServiceUnavailable, ""), nil
+	})
+
+	policy := RetryPolicy{Retries: 2, Budget: 3}
+	fetch := New(&http.Client{Transport: rt}, time.Second, "", nil, nil, policy, testClock{})
+
+	for range 3 {
+		if _, err := fetch.Fetch(context.Background(), exampleURL); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	}
+
+	if calls != 6 {
+		t.Fatalf("calls = %d; want 6 (3 first attempts + 3 budgeted retries)", calls)
+	}
+}
+
+func TestParseErrorClass(t *testing.T) {
+	t.Parallel()
+
+	if class, err := ParseErrorClass(" TLS "); err != nil || class != ErrorTLS {
+		t.Fatalf("ParseErrorClass() = %q, %v; want %q", class, err, ErrorTLS)
+	}
+
+	if _, err := ParseErrorClass("eof"); err == nil {
+		t.Fatal("expected error for unknown class")
+	}
+}
@@ -0,0 +1,176 @@
+package limiter
+
+import (
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	defaultAdaptiveStep        = 100 * time.Millisecond
+	defaultAdaptiveFactor      = 2.0
+	defaultAdaptiveMaxInterval = 30 * time.Second
+	defaultLatencyFactor       = 3.0
+	latencyWarmupSamples       = 3
+	latencyWeight              = 0.2
+)
+
+// AdaptiveConfig tunes AIMD rate control; zero fields use defaults.
+// Step is both the additive decrease per healthy response and the first backoff
+// interval for a host without a limit. Factor multiplies the interval on backoff,
+// capped at MaxInterval. A response slower than LatencyFactor times the host's
+// average latency counts as a spike.
+type AdaptiveConfig struct {
+	Step          time.Duration
+	Factor        float64
+	MaxInterval   time.Duration
+	LatencyFactor float64
+}
+
+// HostStats summarizes adaptive control for one host.
+type HostStats struct {
+	Host        string
+	Interval    time.Duration
+	MaxInterval time.Duration
+	Backoffs    int
+}
+
+type adaptive struct {
+	config AdaptiveConfig
+	hosts  map[string]*adaptiveHost
+}
+
+type adaptiveHost struct {
+	avgLatency  float64
+	samples     int
+	maxInterval time.Duration
+	backoffs    int
+}
+
+// EnableAdaptive turns on AIMD control of per-host intervals driven by Observe.
+func (r *Registry) EnableAdaptive(config AdaptiveConfig) {
+	if r == nil {
+		return
+	}
+
+	if config.Step <= 0 {
+		config.Step = defaultAdaptiveStep
+	}
+
+	if config.Factor <= 1 {
+		config.Factor = defaultAdaptiveFactor
+	}
+
+	if config.MaxInterval <= 0 {
+		config.MaxInterval = defaultAdaptiveMaxInterval
+	}
+
+	if config.LatencyFactor <= 1 {
+		config.LatencyFactor = defaultLatencyFactor
+	}
+
+	r.mu.Lock()
+	r.adaptive = &adaptive{config: config, hosts: map[string]*adaptiveHost{}}
+	r.mu.Unlock()
+}
+
+// Observe feeds a response into adaptive control. 429, 503 and latency spikes widen
+// the host interval multiplicatively; other responses shrink it by Step toward the floor.
+func (r *Registry) Observe(host string, statusCode int, latency time.Duration) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.adaptive == nil {
+		return
+	}
+
+	key := hostKey(host)
+	state := r.adaptive.host(key)
+	current := r.intervalLocked(key)
+	config := r.adaptive.config
+
+	spike := state.observeLatency(latency, config.LatencyFactor)
+	if isThrottled(statusCode) || spike {
+		state.backoffs++
+		r.setIntervalLocked(key, widen(current, config))
+	} else {
+		r.setIntervalLocked(key, current-config.Step)
+	}
+
+	state.maxInterval = max(state.maxInterval, r.intervalLocked(key))
+}
+
+// AdaptiveStats returns adaptive control stats for every observed host, sorted by host.
+func (r *Registry) AdaptiveStats() []HostStats {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.adaptive == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(r.adaptive.hosts))
+	for key := range r.adaptive.hosts {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	stats := make([]HostStats, 0, len(keys))
+	for _, key := range keys {
+		state := r.adaptive.hosts[key]
+		stats = append(stats, HostStats{
+			Host:        key,
+			Interval:    r.intervalLocked(key),
+			MaxInterval: state.maxInterval,
+			Backoffs:    state.backoffs,
+		})
+	}
+
+	return stats
+}
+
+func (a *adaptive) host(key string) *adaptiveHost {
+	state, ok := a.hosts[key]
+	if !ok {
+		state = &adaptiveHost{}
+		a.hosts[key] = state
+	}
+
+	return state
+}
+
+// observeLatency updates the moving average and reports whether latency is a spike.
+func (h *adaptiveHost) observeLatency(latency time.Duration, factor float64) bool {
+	value := float64(latency)
+	spike := h.samples >= latencyWarmupSamples && h.avgLatency > 0 && value > factor*h.avgLatency
+
+	if h.samples == 0 {
+		h.avgLatency = value
+	} else {
+		h.avgLatency += latencyWeight * (value - h.avgLatency)
+	}
+
+	h.samples++
+
+	return spike
+}
+
+func widen(current time.Duration, config AdaptiveConfig) time.Duration {
+	next := time.Duration(float64(current) * config.Factor)
+	next = max(next, config.Step)
+
+	return min(next, config.MaxInterval)
+}
+
+func isThrottled(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
@@ -0,0 +1,88 @@
+package limiter
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRegistryObserveWithoutAdaptiveIsNoop(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry(nil, 0, 0, nil, &fakeTimer{now: baseTime()})
+	registry.Observe("a.test", http.StatusTooManyRequests, 0)
+
+	if got := registry.Interval("a.test"); got != 0 {
+		t.Fatalf("Interval() = %v; want 0", got)
+	}
+
+	if registry.AdaptiveStats() != nil {
+		t.Fatalf("expected no adaptive stats")
+	}
+}
+
+func TestRegistryAdaptiveAIMD(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry(nil, 0, 0, map[string]time.Duration{"floor.test": 150 * time.Millisecond}, &fakeTimer{now: baseTime()})
+	registry.EnableAdaptive(AdaptiveConfig{Step: 100 * time.Millisecond, MaxInterval: time.Second})
+
+	steps := []struct {
+		status int
+		want   time.Duration
+	}{
+		{status: http.StatusTooManyRequests, want: 100 * time.Millisecond},
+		{status: http.StatusServiceUnavailable, want: 200 * time.Millisecond},
+		{status: http.StatusTooManyRequests, want: 400 * time.Millisecond},
+		{status: http.StatusTooManyRequests, want: 800 * time.Millisecond},
+		{status: http.StatusTooManyRequests, want: time.Second},
+		{status: http.StatusOK, want: 900 * time.Millisecond},
+		{status: http.StatusInternalServerError, want: 800 * time.Millisecond},
+	}
+
+	for i, step := range steps {
+		registry.Observe("a.test", step.status, 0)
+		if got := registry.Interval("a.test"); got != step.want {
+			t.Fatalf("step %d: Interval() = %v; want %v", i, got, step.want)
+		}
+	}
+
+	for range 20 {
+		registry.Observe("a.test", http.StatusOK, 0)
+		registry.Observe("floor.test", http.StatusOK, 0)
+	}
+
+	if got := registry.Interval("a.test"); got != 0 {
+		t.Fatalf("Interval() after recovery = %v; want 0", got)
+	}
+
+	if got := registry.Interval("floor.test"); got != 150*time.Millisecond {
+		t.Fatalf("Interval() below floor = %v; want 150ms", got)
+	}
+
+	stats := registry.AdaptiveStats()
+	if len(stats) != 2 || stats[0].Host != "a.test" || stats[1].Host != "floor.test" {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	if stats[0].Backoffs != 5 || stats[0].MaxInterval != time.Second || stats[0].Interval != 0 {
+		t.Fatalf("unexpected a.test stats: %+v", stats[0])
+	}
+}
+
+func TestRegistryAdaptiveLatencySpike(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry(nil, 0, 0, nil, &fakeTimer{now: baseTime()})
+	registry.EnableAdaptive(AdaptiveConfig{})
+
+	for range latencyWarmupSamples {
+		registry.Observe("a.test", http.StatusOK, 100*time.Millisecond)
+	}
+
+	registry.Observe("a.test", http.StatusOK, 2*time.Second)
+
+	if got := registry.Interval("a.test"); got != defaultAdaptiveStep {
+		t.Fatalf("Interval() after spike = %v; want %v", got, defaultAdaptiveStep)
+	}
+}
@@ -0,0 +1,170 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenBucket allows bursts of up to burst requests and refills one token per interval.
+type TokenBucket struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	tokens   float64
+	last     time.Time
+	clock    Timer
+}
+
+// Reservation is a token taken from a TokenBucket.
+type Reservation struct {
+	bucket   *TokenBucket
+	delay    time.Duration
+	canceled bool
+}
+
+// NewTokenBucket creates a full bucket; burst below 1 is treated as 1.
+// It returns nil when interval <= 0.
+func NewTokenBucket(interval time.Duration, burst int, clock Timer) *TokenBucket {
+	if interval <= 0 {
+		return nil
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	if clock == nil {
+		clock = Clock{}
+	}
+
+	return &TokenBucket{
+		interval: interval,
+		burst:    burst,
+		tokens:   float64(burst),
+		clock:    clock,
+	}
+}
+
+// Reserve takes a token and reports how long the caller must wait before using it.
+func (b *TokenBucket) Reserve() *Reservation {
+	if b == nil {
+		return &Reservation{}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(b.clock.Now())
+	b.tokens--
+
+	delay := time.Duration(0)
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens * float64(b.interval))
+	}
+
+	return &Reservation{bucket: b, delay: delay}
+}
+
+// Wait blocks until a token is available or the context is canceled.
+// A canceled wait returns its token to the bucket.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	reservation := b.Reserve()
+	if reservation.Delay() == 0 {
+		return nil
+	}
+
+	if err := b.clock.Sleep(ctx, reservation.Delay()); err != nil {
+		reservation.Cancel()
+
+		return err
+	}
+
+	return nil
+}
+
+// Interval returns the time needed to refill one token.
+func (b *TokenBucket) Interval() time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.interval
+}
+
+// SetInterval changes the refill rate; tokens earned so far are kept.
+func (b *TokenBucket) SetInterval(interval time.Duration) {
+	if b == nil || interval <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(b.clock.Now())
+	b.interval = interval
+}
+
+// Burst returns the bucket capacity.
+func (b *TokenBucket) Burst() int {
+	if b == nil {
+		return 0
+	}
+
+	return b.burst
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	if b.last.IsZero() {
+		b.last = now
+
+		return
+	}
+
+	elapsed := now.Sub(b.last)
+	if elapsed <= 0 {
+		return
+	}
+
+	b.tokens += float64(elapsed) / float64(b.interval)
+	if b.tokens > float64(b.burst) {
+		b.tokens = float64(b.burst)
+	}
+
+	b.last = now
+}
+
+// Delay returns how long to wait before the reserved request may be sent.
+func (r *Reservation) Delay() time.Duration {
+	if r == nil {
+		return 0
+	}
+
+	return r.delay
+}
+
+// Cancel returns the token to the bucket; calling it more than once has no effect.
+func (r *Reservation) Cancel() {
+	if r == nil || r.bucket == nil || r.canceled {
+		return
+	}
+
+	r.canceled = true
+
+	bucket := r.bucket
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+
+	bucket.refill(bucket.clock.Now())
+	bucket.tokens++
+	if bucket.tokens > float64(bucket.burst) {
+		bucket.tokens = float64(bucket.burst)
+	}
+}
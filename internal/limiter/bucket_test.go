@@ -0,0 +1,130 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewTokenBucket(t *testing.T) {
+	t.Parallel()
+
+	if NewTokenBucket(0, 5, nil) != nil {
+		t.Fatalf("expected nil bucket for zero interval")
+	}
+
+	bucket := NewTokenBucket(time.Second, 0, nil)
+	if bucket == nil || bucket.Burst() != 1 {
+		t.Fatalf("expected burst to default to 1")
+	}
+}
+
+func TestTokenBucketReserveBurstThenSpacing(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeTimer{now: baseTime()}
+	bucket := NewTokenBucket(100*time.Millisecond, 3, clock)
+
+	want := []time.Duration{0, 0, 0, 100 * time.Millisecond, 200 * time.Millisecond}
+	for i, wantDelay := range want {
+		if got := bucket.Reserve().Delay(); got != wantDelay {
+			t.Fatalf("reservation %d delay = %v; want %v", i, got, wantDelay)
+		}
+	}
+}
+
+func TestTokenBucketRefillIsCappedAtBurst(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeTimer{now: baseTime()}
+	bucket := NewTokenBucket(100*time.Millisecond, 2, clock)
+
+	bucket.Reserve()
+	bucket.Reserve()
+
+	clock.now = clock.now.Add(time.Hour)
+
+	for i := range 2 {
+		if got := bucket.Reserve().Delay(); got != 0 {
+			t.Fatalf("reservation %d after refill delay = %v; want 0", i, got)
+		}
+	}
+
+	if got := bucket.Reserve().Delay(); got != 100*time.Millisecond {
+		t.Fatalf("delay after burst = %v; want 100ms", got)
+	}
+}
+
+func TestTokenBucketCancelReturnsToken(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeTimer{now: baseTime()}
+	bucket := NewTokenBucket(100*time.Millisecond, 1, clock)
+
+	bucket.Reserve()
+
+	reservation := bucket.Reserve()
+	if reservation.Delay() != 100*time.Millisecond {
+		t.Fatalf("delay = %v; want 100ms", reservation.Delay())
+	}
+
+	reservation.Cancel()
+	reservation.Cancel()
+
+	if got := bucket.Reserve().Delay(); got != 100*time.Millisecond {
+		t.Fatalf("delay after cancel = %v; want 100ms", got)
+	}
+}
+
+func TestTokenBucketWaitCanceledRestoresToken(t *testing.T) {
+	t.Parallel()
+
+	errSleep := errors.New("sleep failed")
+	clock := &fakeTimer{now: baseTime(), sleepErr: errSleep}
+	bucket := NewTokenBucket(100*time.Millisecond, 1, clock)
+	ctx := context.Background()
+
+	if err := bucket.Wait(ctx); err != nil {
+		t.Fatalf("unexpected error on first wait: %v", err)
+	}
+
+	if err := bucket.Wait(ctx); !errors.Is(err, errSleep) {
+		t.Fatalf("expected sleep error, got %v", err)
+	}
+
+	if got := bucket.Reserve().Delay(); got != 100*time.Millisecond {
+		t.Fatalf("delay after failed wait = %v; want 100ms", got)
+	}
+}
+
+func TestTokenBucketNil(t *testing.T) {
+	t.Parallel()
+
+	var bucket *TokenBucket
+	if err := bucket.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if bucket.Reserve().Delay() != 0 || bucket.Interval() != 0 {
+		t.Fatalf("nil bucket must not delay")
+	}
+
+	bucket.SetInterval(time.Second)
+}
+
+func TestNewRate(t *testing.T) {
+	t.Parallel()
+
+	if NewRate(0, 5, nil) != nil {
+		t.Fatalf("expected nil limiter for zero interval")
+	}
+
+	if _, ok := NewRate(time.Second, 1, nil).(*Limiter); !ok {
+		t.Fatalf("expected *Limiter for burst 1")
+	}
+
+	if _, ok := NewRate(time.Second, 4, nil).(*TokenBucket); !ok {
+		t.Fatalf("expected *TokenBucket for burst 4")
+	}
+}
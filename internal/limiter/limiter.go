@@ -77,3 +77,47 @@ func (l *Limiter) Wait(ctx context.Context) error {
 	
 	return nil
 }
+
+// Interval returns the current minimum delay between requests.
+func (l *Limiter) Interval() time.Duration {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.interval
+}
+
+// SetInterval changes the minimum delay used for subsequent requests.
+func (l *Limiter) SetInterval(interval time.Duration) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	l.interval = interval
+	l.mu.Unlock()
+}
+
+// RateLimiter is implemented by Limiter and TokenBucket.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+	Interval() time.Duration
+	SetInterval(interval time.Duration)
+}
+
+// NewRate returns a Limiter when burst <= 1 and a TokenBucket otherwise.
+// It returns nil when interval <= 0.
+func NewRate(interval time.Duration, burst int, clock Timer) RateLimiter {
+	if interval <= 0 {
+		return nil
+	}
+
+	if burst > 1 {
+		return NewTokenBucket(interval, burst, clock)
+	}
+
+	return NewWithTimer(interval, clock)
+}
@@ -0,0 +1,223 @@
+package limiter
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Registry keeps one rate limiter per host and an optional global ceiling shared by all hosts.
+// Each host has a floor interval (its configured rate, raised by Raise) that adaptive
+// control never goes below.
+type Registry struct {
+	mu              sync.Mutex
+	clock           Timer
+	global          RateLimiter
+	defaultInterval time.Duration
+	burst           int
+	overrides       map[string]time.Duration
+	hosts           map[string]RateLimiter
+	floors          map[string]time.Duration
+	pausedUntil     map[string]time.Time
+	adaptive        *adaptive
+}
+
+// NewRegistry creates a registry; hosts use defaultInterval unless overrides names them.
+// Host limiters are token buckets when burst > 1 (see NewRate).
+// Host keys are matched case-insensitively without the port. A nil global disables the ceiling.
+func NewRegistry(
+	global RateLimiter,
+	defaultInterval time.Duration,
+	burst int,
+	overrides map[string]time.Duration,
+	clock Timer,
+) *Registry {
+	if clock == nil {
+		clock = Clock{}
+	}
+
+	normalized := make(map[string]time.Duration, len(overrides))
+	for host, interval := range overrides {
+		normalized[hostKey(host)] = interval
+	}
+
+	return &Registry{
+		clock:           clock,
+		global:          global,
+		defaultInterval: defaultInterval,
+		burst:           burst,
+		overrides:       normalized,
+		hosts:           map[string]RateLimiter{},
+		floors:          map[string]time.Duration{},
+		pausedUntil:     map[string]time.Time{},
+	}
+}
+
+// Wait blocks until both the global ceiling and the host limiter allow a request
+// and any pause set for the host has passed.
+// The host is reserved last so its spacing matches the moment the request is sent.
+func (r *Registry) Wait(ctx context.Context, host string) error {
+	if r == nil {
+		return nil
+	}
+
+	if r.global != nil {
+		if err := r.global.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := r.waitPause(ctx, host); err != nil {
+		return err
+	}
+
+	hostLimiter := r.forHost(host)
+	if hostLimiter == nil {
+		return nil
+	}
+
+	return hostLimiter.Wait(ctx)
+}
+
+// Interval returns the current interval for host.
+func (r *Registry) Interval(host string) time.Duration {
+	if r == nil {
+		return 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.intervalLocked(hostKey(host))
+}
+
+// Raise makes the interval and the floor for host at least interval.
+func (r *Registry) Raise(host string, interval time.Duration) {
+	if r == nil || interval <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := hostKey(host)
+	if interval > r.floorLocked(key) {
+		r.floors[key] = interval
+	}
+
+	if interval > r.intervalLocked(key) {
+		r.setIntervalLocked(key, interval)
+	}
+}
+
+// SetInterval changes the interval for host, never going below its floor.
+func (r *Registry) SetInterval(host string, interval time.Duration) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.setIntervalLocked(hostKey(host), interval)
+}
+
+// Pause holds back every request to host for d, for example after a Retry-After header.
+// An earlier pause is only ever extended, never shortened.
+func (r *Registry) Pause(host string, d time.Duration) {
+	if r == nil || d <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := hostKey(host)
+	until := r.clock.Now().Add(d)
+	if until.After(r.pausedUntil[key]) {
+		r.pausedUntil[key] = until
+	}
+}
+
+func (r *Registry) waitPause(ctx context.Context, host string) error {
+	r.mu.Lock()
+	until := r.pausedUntil[hostKey(host)]
+	r.mu.Unlock()
+
+	remaining := until.Sub(r.clock.Now())
+	if remaining <= 0 {
+		return nil
+	}
+
+	return r.clock.Sleep(ctx, remaining)
+}
+
+func (r *Registry) forHost(host string) RateLimiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.limiterLocked(hostKey(host))
+}
+
+func (r *Registry) limiterLocked(key string) RateLimiter {
+	if hostLimiter, ok := r.hosts[key]; ok {
+		return hostLimiter
+	}
+
+	hostLimiter := NewRate(r.floorLocked(key), r.burst, r.clock)
+	r.hosts[key] = hostLimiter
+
+	return hostLimiter
+}
+
+func (r *Registry) intervalLocked(key string) time.Duration {
+	hostLimiter := r.limiterLocked(key)
+	if hostLimiter == nil {
+		return 0
+	}
+
+	return hostLimiter.Interval()
+}
+
+func (r *Registry) setIntervalLocked(key string, interval time.Duration) {
+	interval = max(interval, r.floorLocked(key))
+
+	hostLimiter := r.limiterLocked(key)
+	switch {
+	case interval <= 0:
+		r.hosts[key] = nil
+	case hostLimiter == nil:
+		r.hosts[key] = NewRate(interval, r.burst, r.clock)
+	default:
+		hostLimiter.SetInterval(interval)
+	}
+}
+
+func (r *Registry) floorLocked(key string) time.Duration {
+	if floor, ok := r.floors[key]; ok {
+		return floor
+	}
+
+	if interval, ok := r.overrides[key]; ok {
+		return interval
+	}
+
+	return r.defaultInterval
+}
+
+func hostKey(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+
+	if strings.HasPrefix(host, "[") {
+		if end := strings.Index(host, "]"); end >= 0 {
+			return host[1:end]
+		}
+	}
+
+	if idx := strings.LastIndex(host, ":"); idx >= 0 && strings.Count(host, ":") == 1 {
+		return host[:idx]
+	}
+
+	return host
+}
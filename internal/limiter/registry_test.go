@@ -0,0 +1,137 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRegistryNil(t *testing.T) {
+	t.Parallel()
+
+	var registry *Registry
+	if err := registry.Wait(context.Background(), "example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := registry.Interval("example.com"); got != 0 {
+		t.Fatalf("Interval() = %v; want 0", got)
+	}
+
+	registry.Raise("example.com", time.Second)
+	registry.Pause("example.com", time.Second)
+}
+
+func TestRegistryHostsAreIndependent(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeTimer{now: baseTime()}
+	registry := NewRegistry(nil, 100*time.Millisecond, 0, nil, clock)
+	ctx := context.Background()
+
+	for _, host := range []string{"a.test", "b.test", "a.test:443"} {
+		if err := registry.Wait(ctx, host); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != 100*time.Millisecond {
+		t.Fatalf("sleeps = %v; want one 100ms sleep for repeated host", clock.sleeps)
+	}
+}
+
+func TestRegistryOverrides(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeTimer{now: baseTime()}
+	registry := NewRegistry(nil, time.Second, 0, map[string]time.Duration{
+		"Example.COM": 50 * time.Millisecond,
+		"free.test":   0,
+	}, clock)
+
+	tests := []struct {
+		host string
+		want time.Duration
+	}{
+		{host: "example.com:8443", want: 50 * time.Millisecond},
+		{host: "free.test", want: 0},
+		{host: "other.test", want: time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := registry.Interval(tt.host); got != tt.want {
+			t.Fatalf("Interval(%q) = %v; want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestRegistryGlobalCeiling(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeTimer{now: baseTime()}
+	global := NewWithTimer(200*time.Millisecond, clock)
+	registry := NewRegistry(global, 0, 0, nil, clock)
+	ctx := context.Background()
+
+	for _, host := range []string{"a.test", "b.test"} {
+		if err := registry.Wait(ctx, host); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != 200*time.Millisecond {
+		t.Fatalf("sleeps = %v; want one 200ms sleep from the global ceiling", clock.sleeps)
+	}
+}
+
+func TestRegistryRaise(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeTimer{now: baseTime()}
+	registry := NewRegistry(nil, 0, 0, nil, clock)
+
+	registry.Raise("a.test", time.Second)
+	if got := registry.Interval("a.test"); got != time.Second {
+		t.Fatalf("Interval() = %v; want 1s", got)
+	}
+
+	registry.Raise("a.test", 100*time.Millisecond)
+	if got := registry.Interval("a.test"); got != time.Second {
+		t.Fatalf("Raise must not lower the interval, got %v", got)
+	}
+
+	registry.Raise("a.test", 2*time.Second)
+	if got := registry.Interval("a.test"); got != 2*time.Second {
+		t.Fatalf("Interval() = %v; want 2s", got)
+	}
+}
+
+func TestRegistryPause(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeTimer{now: baseTime()}
+	registry := NewRegistry(nil, 0, 0, nil, clock)
+	ctx := context.Background()
+
+	registry.Pause("a.test", 3*time.Second)
+	registry.Pause("a.test", time.Second)
+
+	for _, host := range []string{"b.test", "A.test:443"} {
+		if err := registry.Wait(ctx, host); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != 3*time.Second {
+		t.Fatalf("sleeps = %v; want one 3s sleep for the paused host", clock.sleeps)
+	}
+
+	clock.now = clock.now.Add(3 * time.Second)
+	if err := registry.Wait(ctx, "a.test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(clock.sleeps) != 1 {
+		t.Fatalf("sleeps = %v; want no sleep once the pause has passed", clock.sleeps)
+	}
+}
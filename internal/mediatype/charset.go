@@ -0,0 +1,66 @@
+package mediatype
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	utf8Name     = "utf-8"
+	fallbackName = "windows-1252"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ToUTF8 converts an HTML body to UTF-8 and returns the name of the detected encoding.
+// The encoding comes from a byte order mark, then the charset parameter of contentType,
+// then a <meta charset> or http-equiv declaration in the first 1024 bytes. Undeclared
+// bodies are UTF-8 when they are valid UTF-8 and windows-1252 otherwise.
+// When truncated is set the body was cut off, and a UTF-8 body that ends in the middle of
+// a character is still UTF-8; the incomplete character is dropped.
+// A leading byte order mark is dropped. Names are the WHATWG canonical labels,
+// for example "utf-8" or "windows-1251".
+func ToUTF8(body []byte, contentType string, truncated bool) ([]byte, string, error) {
+	enc, name, certain := charset.DetermineEncoding(body, contentType)
+
+	complete := body
+	if truncated {
+		complete = trimIncompleteRune(body)
+	}
+
+	if !certain && name == fallbackName && utf8.Valid(complete) {
+		name = utf8Name
+	}
+
+	if name == utf8Name {
+		return bytes.TrimPrefix(complete, utf8BOM), name, nil
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return nil, name, fmt.Errorf("decode %s: %w", name, err)
+	}
+
+	return bytes.TrimPrefix(decoded, utf8BOM), name, nil
+}
+
+// trimIncompleteRune drops the bytes of a UTF-8 character that body ends in the middle of.
+func trimIncompleteRune(body []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(body); i++ {
+		start := len(body) - i
+		if !utf8.RuneStart(body[start]) {
+			continue
+		}
+
+		if utf8.FullRune(body[start:]) {
+			return body
+		}
+
+		return body[:start]
+	}
+
+	return body
+}
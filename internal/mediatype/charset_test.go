@@ -0,0 +1,77 @@
+package mediatype
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToUTF8(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		truncated   bool
+		want        string
+		wantName    string
+	}{
+		{name: "plain ascii", body: "<p>hi</p>", want: "<p>hi</p>", wantName: "utf-8"},
+		{name: "undeclared utf-8", body: "<p>привет</p>", want: "<p>привет</p>", wantName: "utf-8"},
+		{name: "utf-8 bom is dropped", body: "\xEF\xBB\xBF<p>hi</p>", want: "<p>hi</p>", wantName: "utf-8"},
+		{
+			name:        "header charset",
+			contentType: "text/html; charset=windows-1251",
+			body:        "<p>\xEF\xF0\xE8\xE2\xE5\xF2</p>",
+			want:        "<p>привет</p>",
+			wantName:    "windows-1251",
+		},
+		{
+			name:     "meta charset",
+			body:     "<meta charset=\"koi8-r\"><p>\xD0\xD2\xC9\xD7\xC5\xD4</p>",
+			want:     "<meta charset=\"koi8-r\"><p>привет</p>",
+			wantName: "koi8-r",
+		},
+		{
+			name:     "meta http-equiv",
+			body:     "<meta http-equiv=\"Content-Type\" content=\"text/html; charset=iso-8859-1\"><p>caf\xE9</p>",
+			want:     "<meta http-equiv=\"Content-Type\" content=\"text/html; charset=iso-8859-1\"><p>café</p>",
+			wantName: "windows-1252",
+		},
+		{
+			name:        "bom beats header",
+			contentType: "text/html; charset=windows-1251",
+			body:        "\xFF\xFE<\x00p\x00>\x00",
+			want:        "<p>",
+			wantName:    "utf-16le",
+		},
+		{name: "undeclared latin-1", body: "<p>caf\xE9</p>", want: "<p>café</p>", wantName: "windows-1252"},
+		{
+			name:      "utf-8 cut mid-character",
+			body:      strings.Repeat(" ", 1024) + "<p>при\xD0",
+			truncated: true,
+			want:      strings.Repeat(" ", 1024) + "<p>при",
+			wantName:  "utf-8",
+		},
+		{
+			name:     "invalid utf-8 that was not cut",
+			body:     strings.Repeat(" ", 1024) + "<p>при\xD0",
+			want:     strings.Repeat(" ", 1024) + "<p>Ð¿Ñ€Ð¸Ð",
+			wantName: "windows-1252",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, name, err := ToUTF8([]byte(tt.body), tt.contentType, tt.truncated)
+			if err != nil {
+				t.Fatalf("ToUTF8() returned error: %v", err)
+			}
+			if string(got) != tt.want || name != tt.wantName {
+				t.Fatalf("ToUTF8() = %q, %q; want %q, %q", got, name, tt.want, tt.wantName)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package mediatype
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	htmlType  = "text/html"
+	xhtmlType = "application/xhtml+xml"
+	unknown   = "application/octet-stream"
+)
+
+// Detect returns the media type of a response without parameters, in lower case.
+// The Content-Type header wins when it names a specific type; a missing, invalid or
+// generic (application/octet-stream) header falls back to sniffing the body.
+func Detect(contentType string, body []byte) string {
+	declared := FromHeader(contentType)
+	if declared != "" && declared != unknown {
+		return declared
+	}
+
+	if len(body) == 0 {
+		return declared
+	}
+
+	return FromHeader(http.DetectContentType(body))
+}
+
+// FromHeader parses a Content-Type header value and returns its media type,
+// or an empty string if the value is missing or invalid.
+func FromHeader(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(mediaType)
+}
+
+// IsHTML reports whether mediaType is an HTML or XHTML document.
+func IsHTML(mediaType string) bool {
+	return mediaType == htmlType || mediaType == xhtmlType
+}
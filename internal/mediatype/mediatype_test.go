@@ -0,0 +1,49 @@
+package mediatype
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{name: "header wins", contentType: "Text/HTML; charset=utf-8", body: "%PDF-1.4", want: "text/html"},
+		{name: "xhtml", contentType: "application/xhtml+xml", want: "application/xhtml+xml"},
+		{name: "pdf header", contentType: "application/pdf", body: "<html></html>", want: "application/pdf"},
+		{name: "missing header sniffs html", body: "<!DOCTYPE html><html></html>", want: "text/html"},
+		{name: "octet-stream sniffs pdf", contentType: "application/octet-stream", body: "%PDF-1.4", want: "application/pdf"},
+		{name: "invalid header sniffs", contentType: ";;", body: `{"a":1}`, want: "text/plain"},
+		{name: "nothing to go on", want: ""},
+		{name: "octet-stream without body", contentType: "application/octet-stream", want: "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := Detect(tt.contentType, []byte(tt.body)); got != tt.want {
+				t.Fatalf("Detect(%q) = %q; want %q", tt.contentType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsHTML(t *testing.T) {
+	t.Parallel()
+
+	for mediaType, want := range map[string]bool{
+		"text/html":             true,
+		"application/xhtml+xml": true,
+		"application/pdf":       false,
+		"application/json":      false,
+		"":                      false,
+	} {
+		if got := IsHTML(mediaType); got != want {
+			t.Fatalf("IsHTML(%q) = %v; want %v", mediaType, got, want)
+		}
+	}
+}
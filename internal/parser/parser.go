@@ -16,6 +16,15 @@ type SEOData struct {
 	HasH1          bool
 }
 
+// LinkRef describes one link element: its href, its text and its tag name, "a" or "area".
+// Text is the element's text, falling back to the alt text of an image inside it and then
+// to its title attribute; for <area> it is the alt attribute.
+type LinkRef struct {
+	URL  string
+	Text string
+	Tag  string
+}
+
 // AssetRef describes an asset reference in HTML.
 type AssetRef struct {
 	URL  string
@@ -23,10 +32,18 @@ type AssetRef struct {
 }
 
 // ParseResult aggregates HTML analysis results.
+// Links holds the href of every <a> and <area> element, and LinkRefs describes the same
+// elements in the same order. FollowLinks holds the links not marked rel="nofollow"; Robots is the content of the
+// <meta name="robots"> tags, joined with commas. Anchors lists the fragments a link can
+// point to: every id attribute and the name attribute of <a> elements, in document order.
 type ParseResult struct {
-	Links  []string
-	SEO    SEOData
-	Assets []AssetRef
+	Links       []string
+	LinkRefs    []LinkRef
+	FollowLinks []string
+	SEO         SEOData
+	Assets      []AssetRef
+	Robots      string
+	Anchors     []string
 }
 
 // ParseHTML parses HTML and extracts links, SEO, and assets.
@@ -37,10 +54,16 @@ func ParseHTML(body []byte) (ParseResult, error) {
 		return ParseResult{}, err
 	}
 
+	refs, followLinks := parseLinks(doc)
+
 	return ParseResult{
-		Links:  parseLinks(doc),
-		SEO:    parseSEO(doc),
-		Assets: parseAssets(doc),
+		Links:       linkURLs(refs),
+		LinkRefs:    refs,
+		FollowLinks: followLinks,
+		SEO:         parseSEO(doc),
+		Assets:      parseAssets(doc),
+		Robots:      parseMetaRobots(doc),
+		Anchors:     parseAnchors(doc),
 	}, nil
 }
 
@@ -88,20 +111,100 @@ func findMetaDescription(doc *goquery.Document) (bool, string) {
 	return found, description
 }
 
-func parseLinks(doc *goquery.Document) []string {
-	links := []string{}
-	doc.Find("a[href]").Each(func(_ int, selection *goquery.Selection) {
+func parseMetaRobots(doc *goquery.Document) string {
+	values := []string{}
+	doc.Find("meta[name]").Each(func(_ int, selection *goquery.Selection) {
+		name, _ := selection.Attr("name")
+		if !strings.EqualFold(strings.TrimSpace(name), "robots") {
+			return
+		}
+
+		if content := strings.TrimSpace(selection.AttrOr("content", "")); content != "" {
+			values = append(values, content)
+		}
+	})
+
+	return strings.Join(values, ", ")
+}
+
+func parseLinks(doc *goquery.Document) ([]LinkRef, []string) {
+	refs := []LinkRef{}
+	followLinks := []string{}
+	doc.Find("a[href], area[href]").Each(func(_ int, selection *goquery.Selection) {
 		href, ok := selection.Attr("href")
 		if !ok {
 			return
 		}
 
-		links = append(links, strings.TrimSpace(href))
+		href = strings.TrimSpace(href)
+		refs = append(refs, LinkRef{
+			URL:  href,
+			Text: linkText(selection),
+			Tag:  goquery.NodeName(selection),
+		})
+
+		if !hasRelToken(selection, "nofollow") {
+			followLinks = append(followLinks, href)
+		}
 	})
 
+	return refs, followLinks
+}
+
+func linkURLs(refs []LinkRef) []string {
+	links := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		links = append(links, ref.URL)
+	}
+
 	return links
 }
 
+func linkText(selection *goquery.Selection) string {
+	if goquery.NodeName(selection) == "area" {
+		return cleanHumanText(selection.AttrOr("alt", ""))
+	}
+
+	if text := cleanHumanText(selection.Text()); text != "" {
+		return text
+	}
+
+	if alt := cleanHumanText(selection.Find("img[alt]").First().AttrOr("alt", "")); alt != "" {
+		return alt
+	}
+
+	return cleanHumanText(selection.AttrOr("title", ""))
+}
+
+func parseAnchors(doc *goquery.Document) []string {
+	anchors := []string{}
+	doc.Find("[id], a[name]").Each(func(_ int, selection *goquery.Selection) {
+		if id := selection.AttrOr("id", ""); id != "" {
+			anchors = append(anchors, id)
+		}
+
+		if goquery.NodeName(selection) != "a" {
+			return
+		}
+
+		if name := selection.AttrOr("name", ""); name != "" {
+			anchors = append(anchors, name)
+		}
+	})
+
+	return anchors
+}
+
+func hasRelToken(selection *goquery.Selection, token string) bool {
+	for _, value := range strings.Fields(selection.AttrOr("rel", "")) {
+		if strings.EqualFold(value, token) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func parseAssets(doc *goquery.Document) []AssetRef {
 	assets := []AssetRef{}
 	assets = append(assets, parseAssetBySelector(doc, "img[src]", "src", "image")...)
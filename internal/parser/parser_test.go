@@ -21,6 +21,21 @@ func TestParseHTML(t *testing.T) {
 			htmlFixture: "parse_full.html",
 			wantFixture: "parse_full_expected.json",
 		},
+		{
+			name:        "robots meta and nofollow links",
+			htmlFixture: "parse_robots.html",
+			wantFixture: "parse_robots_expected.json",
+		},
+		{
+			name:        "id and name anchors",
+			htmlFixture: "parse_anchors.html",
+			wantFixture: "parse_anchors_expected.json",
+		},
+		{
+			name:        "link text and tags",
+			htmlFixture: "parse_links.html",
+			wantFixture: "parse_links_expected.json",
+		},
 		{
 			name:        "missing seo fields",
 			htmlFixture: "parse_missing_seo.html",
@@ -80,7 +95,19 @@ func readParseResultFixture(t *testing.T, filename string) ParseResult {
 }
 
 func equalParseResult(got, want ParseResult) bool {
-	if !equalStrings(got.Links, want.Links) {
+	if !equalStrings(got.Links, want.Links) || !equalStrings(got.FollowLinks, want.FollowLinks) {
+		return false
+	}
+
+	if !equalLinkRefs(got.LinkRefs, want.LinkRefs) {
+		return false
+	}
+
+	if !equalStrings(got.Anchors, want.Anchors) {
+		return false
+	}
+
+	if got.Robots != want.Robots {
 		return false
 	}
 
@@ -101,6 +128,20 @@ func equalParseResult(got, want ParseResult) bool {
 	return true
 }
 
+func equalLinkRefs(got, want []LinkRef) bool {
+	if len(got) != len(want) {
+		return false
+	}
+
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
 func equalStrings(got, want []string) bool {
 	if len(got) != len(want) {
 		return false
@@ -0,0 +1,68 @@
+package robots
+
+import "strings"
+
+// Directives are the indexing rules a page declares in <meta name="robots"> or X-Robots-Tag.
+type Directives struct {
+	NoIndex  bool
+	NoFollow bool
+}
+
+// ParseDirectives reads a comma-separated directive list such as "noindex, nofollow".
+// "none" means both noindex and nofollow. A crawler name prefix, as in "googlebot: noindex,
+// nofollow", scopes every directive after it, up to the next prefix, to that crawler; such
+// directives are ignored because they do not apply to every crawler. Directives with a value,
+// such as "max-image-preview:large", are skipped without affecting the rest.
+func ParseDirectives(value string) Directives {
+	directives := Directives{}
+	scoped := false
+
+	for _, token := range strings.Split(value, ",") {
+		if name, _, ok := strings.Cut(token, ":"); ok && isAgent(name) {
+			scoped = true
+		}
+
+		if scoped {
+			continue
+		}
+
+		switch strings.ToLower(strings.TrimSpace(token)) {
+		case "noindex":
+			directives.NoIndex = true
+		case "nofollow":
+			directives.NoFollow = true
+		case "none":
+			directives.NoIndex = true
+			directives.NoFollow = true
+		}
+	}
+
+	return directives
+}
+
+// Merge returns the directives that apply when both d and other do.
+func (d Directives) Merge(other Directives) Directives {
+	return Directives{
+		NoIndex:  d.NoIndex || other.NoIndex,
+		NoFollow: d.NoFollow || other.NoFollow,
+	}
+}
+
+// isDirective reports whether name is a directive that takes a value after a colon,
+// such as "unavailable_after: 2025-01-01" or "max-snippet: 20", rather than a crawler name.
+func isDirective(name string) bool {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "unavailable_after", "max-snippet", "max-image-preview", "max-video-preview":
+		return true
+	default:
+		return false
+	}
+}
+
+// isAgent reports whether name, the text before a colon, names a crawler. Directive names
+// are not crawlers, and neither is text with spaces, such as the time in a date value.
+func isAgent(name string) bool {
+	name = strings.TrimSpace(name)
+
+	return name != "" && !isDirective(name) && !strings.ContainsAny(name, " \t")
+}
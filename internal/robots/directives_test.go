@@ -0,0 +1,54 @@
+package robots
+
+import "testing"
+
+func TestParseDirectives(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value string
+		want  Directives
+	}{
+		{name: "empty", value: "", want: Directives{}},
+		{name: "index follow", value: "index, follow", want: Directives{}},
+		{name: "noindex", value: "noindex", want: Directives{NoIndex: true}},
+		{name: "both mixed case", value: " NoIndex ,NOFOLLOW", want: Directives{NoIndex: true, NoFollow: true}},
+		{name: "none", value: "none", want: Directives{NoIndex: true, NoFollow: true}},
+		{name: "scoped to a crawler", value: "googlebot: noindex", want: Directives{}},
+		{name: "directive with value", value: "max-snippet: 20, nofollow", want: Directives{NoFollow: true}},
+		{
+			name:  "value directive after the list",
+			value: "noindex, nofollow, max-image-preview:large",
+			want:  Directives{NoIndex: true, NoFollow: true},
+		},
+		{name: "negative snippet length", value: "noindex, max-snippet:-1", want: Directives{NoIndex: true}},
+		{name: "scoped token among others", value: "nofollow, googlebot: noindex", want: Directives{NoFollow: true}},
+		{name: "crawler prefix scopes the list", value: "googlebot: noindex, nofollow", want: Directives{}},
+		{name: "next crawler prefix", value: "googlebot: noindex, otherbot: nofollow", want: Directives{}},
+		{
+			name:  "date with a comma",
+			value: "noindex, unavailable_after: Friday, 25-Jun-2010 15:00:00 PST, nofollow",
+			want:  Directives{NoIndex: true, NoFollow: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := ParseDirectives(tt.value); got != tt.want {
+				t.Fatalf("ParseDirectives(%q) = %+v; want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirectivesMerge(t *testing.T) {
+	t.Parallel()
+
+	got := Directives{NoIndex: true}.Merge(Directives{NoFollow: true})
+	if got != (Directives{NoIndex: true, NoFollow: true}) {
+		t.Fatalf("Merge() = %+v", got)
+	}
+}
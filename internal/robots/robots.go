@@ -0,0 +1,248 @@
+package robots
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rules holds the robots.txt directives that apply to a single user agent.
+// A nil Rules allows everything.
+type Rules struct {
+	rules      []rule
+	crawlDelay time.Duration
+	sitemaps   []string
+}
+
+type rule struct {
+	pattern string
+	allow   bool
+}
+
+type group struct {
+	agents     []string
+	rules      []rule
+	crawlDelay time.Duration
+}
+
+// AllowAll returns rules that permit every path.
+func AllowAll() *Rules {
+	return &Rules{}
+}
+
+// DisallowAll returns rules that block every path.
+func DisallowAll() *Rules {
+	return &Rules{rules: []rule{{pattern: "/", allow: false}}}
+}
+
+// Parse parses a robots.txt body and selects the group matching userAgent.
+// The product token of userAgent (the part before "/") is compared case-insensitively;
+// the "*" group is used when no group names the agent.
+func Parse(body []byte, userAgent string) *Rules {
+	groups, sitemaps := parseGroups(body)
+	token := productToken(userAgent)
+
+	selected := selectGroup(groups, token)
+	if selected == nil {
+		selected = selectGroup(groups, "*")
+	}
+
+	rules := &Rules{sitemaps: sitemaps}
+	if selected != nil {
+		rules.rules = selected.rules
+		rules.crawlDelay = selected.crawlDelay
+	}
+
+	return rules
+}
+
+// Allowed reports whether path (including an optional query) may be fetched.
+// The longest matching pattern wins; Allow wins ties.
+func (r *Rules) Allowed(path string) bool {
+	if r == nil {
+		return true
+	}
+
+	if path == "" {
+		path = "/"
+	}
+
+	bestLen := -1
+	allowed := true
+
+	for _, candidate := range r.rules {
+		if !matchPattern(candidate.pattern, path) {
+			continue
+		}
+
+		patternLen := len(candidate.pattern)
+		if patternLen > bestLen || (patternLen == bestLen && candidate.allow) {
+			bestLen = patternLen
+			allowed = candidate.allow
+		}
+	}
+
+	return allowed
+}
+
+// CrawlDelay returns the Crawl-delay for the selected group, or zero.
+func (r *Rules) CrawlDelay() time.Duration {
+	if r == nil {
+		return 0
+	}
+
+	return r.crawlDelay
+}
+
+// Sitemaps returns the Sitemap URLs listed in the file.
+func (r *Rules) Sitemaps() []string {
+	if r == nil {
+		return nil
+	}
+
+	return r.sitemaps
+}
+
+func parseGroups(body []byte) ([]*group, []string) {
+	groups := []*group{}
+	sitemaps := []string{}
+
+	var current *group
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		key, value, ok := splitLine(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "user-agent":
+			if current == nil || !lastWasAgent {
+				current = &group{}
+				groups = append(groups, current)
+			}
+
+			current.agents = append(current.agents, strings.ToLower(value))
+			lastWasAgent = true
+
+			continue
+		case "sitemap":
+			if value != "" {
+				sitemaps = append(sitemaps, value)
+			}
+		default:
+			applyDirective(current, key, value)
+		}
+
+		lastWasAgent = false
+	}
+
+	return groups, sitemaps
+}
+
+func applyDirective(current *group, key string, value string) {
+	if current == nil {
+		return
+	}
+
+	switch key {
+	case "allow":
+		if value != "" {
+			current.rules = append(current.rules, rule{pattern: value, allow: true})
+		}
+	case "disallow":
+		if value != "" {
+			current.rules = append(current.rules, rule{pattern: value, allow: false})
+		}
+	case "crawl-delay":
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err == nil && seconds > 0 {
+			current.crawlDelay = time.Duration(seconds * float64(time.Second))
+		}
+	}
+}
+
+func splitLine(line string) (string, string, bool) {
+	if idx := strings.IndexByte(line, '#'); idx >= 0 {
+		line = line[:idx]
+	}
+
+	key, value, found := strings.Cut(line, ":")
+	if !found {
+		return "", "", false
+	}
+
+	return strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value), true
+}
+
+func selectGroup(groups []*group, token string) *group {
+	var merged *group
+
+	for _, candidate := range groups {
+		for _, agent := range candidate.agents {
+			if agent != token {
+				continue
+			}
+
+			if merged == nil {
+				merged = &group{}
+			}
+
+			merged.rules = append(merged.rules, candidate.rules...)
+			if candidate.crawlDelay > merged.crawlDelay {
+				merged.crawlDelay = candidate.crawlDelay
+			}
+
+			break
+		}
+	}
+
+	return merged
+}
+
+func productToken(userAgent string) string {
+	token, _, _ := strings.Cut(strings.TrimSpace(userAgent), "/")
+	token, _, _ = strings.Cut(token, " ")
+
+	return strings.ToLower(token)
+}
+
+func matchPattern(pattern string, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+
+	pos := len(parts[0])
+	for _, part := range parts[1:] {
+		idx := strings.Index(path[pos:], part)
+		if idx < 0 {
+			return false
+		}
+
+		pos += idx + len(part)
+	}
+
+	if !anchored {
+		return true
+	}
+
+	if len(parts) > 1 && parts[len(parts)-1] == "" {
+		return true
+	}
+
+	if len(parts) > 1 {
+		return strings.HasSuffix(path, parts[len(parts)-1])
+	}
+
+	return pos == len(path)
+}
@@ -0,0 +1,104 @@
+package robots
+
+import (
+	"testing"
+	"time"
+)
+
+const sampleRobots = `
+# comment line
+User-agent: *
+Disallow: /private
+Allow: /private/public
+Crawl-delay: 2
+
+User-agent: hexlet-go-crawler
+User-agent: other-bot
+Disallow: /admin
+Disallow: /*.pdf$
+Allow: /admin/help
+Crawl-delay: 0.5
+
+Sitemap: https://example.com/sitemap.xml
+`
+
+func TestParseSelectsGroup(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		userAgent string
+		path      string
+		want      bool
+	}{
+		{name: "named group disallow", userAgent: "hexlet-go-crawler/1.0", path: "/admin/users", want: false},
+		{name: "named group longest allow", userAgent: "hexlet-go-crawler/1.0", path: "/admin/help", want: true},
+		{name: "named group ignores star group", userAgent: "hexlet-go-crawler/1.0", path: "/private", want: true},
+		{name: "case-insensitive agent", userAgent: "Hexlet-Go-Crawler", path: "/admin", want: false},
+		{name: "wildcard with anchor", userAgent: "hexlet-go-crawler/1.0", path: "/docs/file.pdf", want: false},
+		{name: "anchor rejects suffix", userAgent: "hexlet-go-crawler/1.0", path: "/docs/file.pdf?x=1", want: true},
+		{name: "fallback group disallow", userAgent: "unknown-bot/2.0", path: "/private/data", want: false},
+		{name: "fallback group allow wins longer", userAgent: "unknown-bot/2.0", path: "/private/public/x", want: true},
+		{name: "empty path is root", userAgent: "unknown-bot/2.0", path: "", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rules := Parse([]byte(sampleRobots), tt.userAgent)
+			if got := rules.Allowed(tt.path); got != tt.want {
+				t.Fatalf("Allowed(%q) = %v; want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCrawlDelayAndSitemaps(t *testing.T) {
+	t.Parallel()
+
+	named := Parse([]byte(sampleRobots), "hexlet-go-crawler/1.0")
+	if got := named.CrawlDelay(); got != 500*time.Millisecond {
+		t.Fatalf("CrawlDelay() = %v; want %v", got, 500*time.Millisecond)
+	}
+
+	fallback := Parse([]byte(sampleRobots), "unknown-bot")
+	if got := fallback.CrawlDelay(); got != 2*time.Second {
+		t.Fatalf("CrawlDelay() = %v; want %v", got, 2*time.Second)
+	}
+
+	sitemaps := named.Sitemaps()
+	if len(sitemaps) != 1 || sitemaps[0] != "https://example.com/sitemap.xml" {
+		t.Fatalf("Sitemaps() = %v", sitemaps)
+	}
+}
+
+func TestNilAndPresetRules(t *testing.T) {
+	t.Parallel()
+
+	var rules *Rules
+	if !rules.Allowed("/anything") {
+		t.Fatalf("nil rules must allow everything")
+	}
+
+	if rules.CrawlDelay() != 0 || rules.Sitemaps() != nil {
+		t.Fatalf("nil rules must have no delay and no sitemaps")
+	}
+
+	if !AllowAll().Allowed("/x") {
+		t.Fatalf("AllowAll must allow")
+	}
+
+	if DisallowAll().Allowed("/x") {
+		t.Fatalf("DisallowAll must disallow")
+	}
+}
+
+func TestParseNoMatchingGroup(t *testing.T) {
+	t.Parallel()
+
+	rules := Parse([]byte("User-agent: other\nDisallow: /\n"), "hexlet-go-crawler")
+	if !rules.Allowed("/page") {
+		t.Fatalf("rules without a matching group must allow everything")
+	}
+}
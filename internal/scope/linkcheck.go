@@ -0,0 +1,32 @@
+package scope
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LinkCheck selects which links found on crawled pages are checked.
+type LinkCheck string
+
+// Link check modes understood by ParseLinkCheck.
+const (
+	// LinkCheckAll checks links on the crawled site and links to other sites.
+	LinkCheckAll LinkCheck = "all"
+	// LinkCheckInternal checks only links on the crawled site.
+	LinkCheckInternal LinkCheck = "internal"
+	// LinkCheckNone checks no links; links on the crawled site are still crawled.
+	LinkCheckNone LinkCheck = "none"
+)
+
+// ParseLinkCheck validates a link check mode name; an empty name means LinkCheckAll.
+func ParseLinkCheck(value string) (LinkCheck, error) {
+	mode := LinkCheck(strings.ToLower(strings.TrimSpace(value)))
+	switch mode {
+	case "":
+		return LinkCheckAll, nil
+	case LinkCheckAll, LinkCheckInternal, LinkCheckNone:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown link check mode %q", value)
+	}
+}
@@ -0,0 +1,19 @@
+package scope
+
+import "testing"
+
+func TestParseLinkCheck(t *testing.T) {
+	t.Parallel()
+
+	if mode, err := ParseLinkCheck(""); err != nil || mode != LinkCheckAll {
+		t.Fatalf("ParseLinkCheck(\"\") = %q, %v; want %q", mode, err, LinkCheckAll)
+	}
+
+	if mode, err := ParseLinkCheck(" Internal "); err != nil || mode != LinkCheckInternal {
+		t.Fatalf("ParseLinkCheck() = %q, %v; want %q", mode, err, LinkCheckInternal)
+	}
+
+	if _, err := ParseLinkCheck("external"); err == nil {
+		t.Fatal("expected error for unknown mode")
+	}
+}
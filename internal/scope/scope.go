@@ -0,0 +1,112 @@
+package scope
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const regexPrefix = "re:"
+
+// Patterns filters URLs by their path and query. A nil Patterns matches every URL.
+type Patterns struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// NewPatterns compiles include and exclude patterns, or returns nil when both are empty.
+// A pattern prefixed with "re:" is a regular expression that may match anywhere;
+// any other pattern is a glob that must match the whole path and query, where
+// "*" matches any run of characters, including "/" and "?".
+func NewPatterns(include, exclude []string) (*Patterns, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+
+	includeRegexps, err := compileAll(include)
+	if err != nil {
+		return nil, fmt.Errorf("include: %w", err)
+	}
+
+	excludeRegexps, err := compileAll(exclude)
+	if err != nil {
+		return nil, fmt.Errorf("exclude: %w", err)
+	}
+
+	return &Patterns{include: includeRegexps, exclude: excludeRegexps}, nil
+}
+
+// Match reports whether rawURL matches an include pattern, or there are none,
+// and matches no exclude pattern. URLs that cannot be parsed never match.
+func (p *Patterns) Match(rawURL string) bool {
+	if p == nil {
+		return true
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	target := pathAndQuery(parsed)
+	if len(p.include) > 0 && !matchAny(p.include, target) {
+		return false
+	}
+
+	return !matchAny(p.exclude, target)
+}
+
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		re, err := compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	if expr, ok := strings.CutPrefix(pattern, regexPrefix); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+
+		return re, nil
+	}
+
+	quoted := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+
+	return regexp.MustCompile("^" + quoted + "$"), nil
+}
+
+func matchAny(regexps []*regexp.Regexp, target string) bool {
+	for _, re := range regexps {
+		if re.MatchString(target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pathAndQuery returns the escaped path, "/" for an empty one, followed by "?" and the raw query if any.
+func pathAndQuery(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	if u.RawQuery == "" {
+		return path
+	}
+
+	return path + "?" + u.RawQuery
+}
@@ -0,0 +1,59 @@
+package scope
+
+import "testing"
+
+func TestPatternsMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		url     string
+		want    bool
+	}{
+		{name: "no patterns", url: "https://example.com/admin", want: true},
+		{name: "glob exclude prefix", exclude: []string{"/admin*"}, url: "https://example.com/admin/users", want: false},
+		{name: "glob is anchored", exclude: []string{"/admin"}, url: "https://example.com/admin/users", want: true},
+		{name: "glob query", exclude: []string{"/search?q=*"}, url: "https://example.com/search?q=shoes", want: false},
+		{name: "glob question mark is literal", exclude: []string{"/search?q=*"}, url: "https://example.com/searchXq=shoes", want: true},
+		{name: "root path", include: []string{"/"}, url: "https://example.com", want: true},
+		{name: "regex anywhere", exclude: []string{`re:/calendar/\d{4}`}, url: "https://example.com/events/calendar/2024/01", want: false},
+		{name: "include miss", include: []string{"/docs/*"}, url: "https://example.com/blog/post", want: false},
+		{name: "include hit", include: []string{"/docs/*", "/blog/*"}, url: "https://example.com/blog/post", want: true},
+		{name: "exclude beats include", include: []string{"/docs/*"}, exclude: []string{"*draft*"}, url: "https://example.com/docs/draft-1", want: false},
+		{name: "host is ignored", exclude: []string{"re:example"}, url: "https://example.com/page", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			patterns, err := NewPatterns(tt.include, tt.exclude)
+			if err != nil {
+				t.Fatalf("NewPatterns returned error: %v", err)
+			}
+
+			if got := patterns.Match(tt.url); got != tt.want {
+				t.Fatalf("Match(%q) = %v; want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPatternsInvalidRegex(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewPatterns(nil, []string{"re:("}); err == nil {
+		t.Fatal("expected error for invalid regular expression")
+	}
+}
+
+func TestNewPatternsEmpty(t *testing.T) {
+	t.Parallel()
+
+	patterns, err := NewPatterns(nil, nil)
+	if err != nil || patterns != nil {
+		t.Fatalf("NewPatterns(nil, nil) = %v, %v; want nil, nil", patterns, err)
+	}
+}
@@ -0,0 +1,160 @@
+package scope
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Mode selects which URLs count as the crawled site.
+type Mode string
+
+// Scope modes understood by NewSites.
+const (
+	// ModeOrigin accepts only the root origin: same scheme, host and port.
+	ModeOrigin Mode = "origin"
+	// ModeDomain accepts every host under the root's registrable domain, for example
+	// www.example.com and docs.example.com for a root on example.com.
+	ModeDomain Mode = "domain"
+	// ModeList accepts the root origin and an explicit list of other origins.
+	ModeList Mode = "list"
+)
+
+// ParseMode validates a scope mode name; an empty name means ModeOrigin.
+func ParseMode(value string) (Mode, error) {
+	mode := Mode(strings.ToLower(strings.TrimSpace(value)))
+	switch mode {
+	case "":
+		return ModeOrigin, nil
+	case ModeOrigin, ModeDomain, ModeList:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown scope mode %q", value)
+	}
+}
+
+type origin struct {
+	scheme string
+	host   string
+}
+
+// Sites decides whether a URL belongs to the crawled site.
+type Sites struct {
+	mode         Mode
+	root         origin
+	domain       string
+	allowed      []origin
+	ignoreScheme bool
+}
+
+// NewSites builds the site scope around root. allowed lists extra origins such as
+// "https://docs.example.com" and is accepted only in ModeList. With ignoreScheme,
+// http and https URLs of the same host are treated as the same site.
+func NewSites(mode Mode, root *url.URL, allowed []string, ignoreScheme bool) (*Sites, error) {
+	if len(allowed) > 0 && mode != ModeList {
+		return nil, fmt.Errorf("allowed origins require scope mode %q", ModeList)
+	}
+
+	sites := &Sites{
+		mode:         mode,
+		root:         originOf(root),
+		domain:       registrableDomain(root.Hostname()),
+		ignoreScheme: ignoreScheme,
+	}
+
+	for _, value := range allowed {
+		parsed, err := url.Parse(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed origin %q: %w", value, err)
+		}
+
+		if parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid allowed origin %q: missing scheme or host", value)
+		}
+
+		sites.allowed = append(sites.allowed, originOf(parsed))
+	}
+
+	return sites, nil
+}
+
+// ExactOrigin returns Sites that accept only root's origin.
+func ExactOrigin(root *url.URL) *Sites {
+	return &Sites{mode: ModeOrigin, root: originOf(root)}
+}
+
+// Contains reports whether rawURL belongs to the site. Hosts are compared
+// case-insensitively and default ports are ignored.
+func (s *Sites) Contains(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+
+	target := originOf(parsed)
+
+	switch s.mode {
+	case ModeDomain:
+		return s.sameScheme(target) && registrableDomain(parsed.Hostname()) == s.domain
+	case ModeList:
+		return s.sameOrigin(s.root, target) || s.inList(target)
+	default:
+		return s.sameOrigin(s.root, target)
+	}
+}
+
+func (s *Sites) inList(target origin) bool {
+	for _, allowed := range s.allowed {
+		if s.sameOrigin(allowed, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Sites) sameOrigin(want, target origin) bool {
+	return want.host == target.host && (s.ignoreScheme || want.scheme == target.scheme)
+}
+
+func (s *Sites) sameScheme(target origin) bool {
+	return s.ignoreScheme || target.scheme == s.root.scheme
+}
+
+func originOf(u *url.URL) origin {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+
+	if port := u.Port(); port != "" && port != defaultPort(scheme) {
+		host = net.JoinHostPort(host, port)
+	}
+
+	return origin{scheme: scheme, host: host}
+}
+
+func defaultPort(scheme string) string {
+	switch scheme {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	default:
+		return ""
+	}
+}
+
+// registrableDomain returns the public suffix plus one label, or the host itself
+// for IP addresses, localhost and other names without a known suffix.
+func registrableDomain(host string) string {
+	host = strings.ToLower(host)
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+
+	return domain
+}
@@ -0,0 +1,119 @@
+package scope
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestSitesContains(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		mode         Mode
+		root         string
+		allowed      []string
+		ignoreScheme bool
+		url          string
+		want         bool
+	}{
+		{name: "origin same", mode: ModeOrigin, root: "https://example.com", url: "https://EXAMPLE.com/a", want: true},
+		{name: "origin default port", mode: ModeOrigin, root: "https://example.com", url: "https://example.com:443/a", want: true},
+		{name: "origin other port", mode: ModeOrigin, root: "https://example.com", url: "https://example.com:8443/a", want: false},
+		{name: "origin other scheme", mode: ModeOrigin, root: "https://example.com", url: "http://example.com/a", want: false},
+		{name: "origin ignore scheme", mode: ModeOrigin, root: "https://example.com", ignoreScheme: true, url: "http://example.com/a", want: true},
+		{name: "origin subdomain", mode: ModeOrigin, root: "https://example.com", url: "https://www.example.com/a", want: false},
+		{name: "domain subdomain", mode: ModeDomain, root: "https://www.example.com", url: "https://docs.example.com/a", want: true},
+		{name: "domain apex", mode: ModeDomain, root: "https://www.example.com", url: "https://example.com/a", want: true},
+		{name: "domain other", mode: ModeDomain, root: "https://example.com", url: "https://example.org/a", want: false},
+		{name: "domain public suffix", mode: ModeDomain, root: "https://a.github.io", url: "https://b.github.io/", want: false},
+		{name: "domain keeps scheme", mode: ModeDomain, root: "https://example.com", url: "http://docs.example.com/", want: false},
+		{name: "domain localhost", mode: ModeDomain, root: "http://localhost:8080", url: "http://localhost:9090/", want: true},
+		{name: "list root", mode: ModeList, root: "https://example.com", allowed: []string{"https://docs.example.net"}, url: "https://example.com/a", want: true},
+		{name: "list allowed", mode: ModeList, root: "https://example.com", allowed: []string{"https://docs.example.net"}, url: "https://docs.example.net/a", want: true},
+		{name: "list not allowed", mode: ModeList, root: "https://example.com", allowed: []string{"https://docs.example.net"}, url: "https://blog.example.net/a", want: false},
+		{name: "relative url", mode: ModeOrigin, root: "https://example.com", url: "/a", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			root, err := url.Parse(tt.root)
+			if err != nil {
+				t.Fatalf("parse root: %v", err)
+			}
+
+			sites, err := NewSites(tt.mode, root, tt.allowed, tt.ignoreScheme)
+			if err != nil {
+				t.Fatalf("NewSites returned error: %v", err)
+			}
+
+			if got := sites.Contains(tt.url); got != tt.want {
+				t.Fatalf("Contains(%q) = %v; want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSitesErrors(t *testing.T) {
+	t.Parallel()
+
+	root, _ := url.Parse("https://example.com")
+
+	if _, err := NewSites(ModeOrigin, root, []string{"https://docs.example.com"}, false); err == nil {
+		t.Fatal("expected error for allowed origins outside list mode")
+	}
+
+	if _, err := NewSites(ModeList, root, []string{"docs.example.com"}, false); err == nil {
+		t.Fatal("expected error for an origin without scheme")
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	t.Parallel()
+
+	if mode, err := ParseMode(""); err != nil || mode != ModeOrigin {
+		t.Fatalf("ParseMode(\"\") = %q, %v; want %q", mode, err, ModeOrigin)
+	}
+
+	if mode, err := ParseMode(" Domain "); err != nil || mode != ModeDomain {
+		t.Fatalf("ParseMode() = %q, %v; want %q", mode, err, ModeDomain)
+	}
+
+	if _, err := ParseMode("subdomains"); err == nil {
+		t.Fatal("expected error for unknown mode")
+	}
+}
+
+func TestExactOrigin(t *testing.T) {
+	t.Parallel()
+
+	root, err := url.Parse("https://example.com:8443/root")
+	if err != nil {
+		t.Fatalf("parse root: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{name: "same scheme host and port", url: "https://example.com:8443/a", want: true},
+		{name: "different scheme", url: "http://example.com:8443/a", want: false},
+		{name: "different host", url: "https://other.com:8443/a", want: false},
+		{name: "different port", url: "https://example.com/a", want: false},
+		{name: "invalid url", url: "http://[::1", want: false},
+		{name: "relative url", url: "/local", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := ExactOrigin(root).Contains(tt.url); got != tt.want {
+				t.Fatalf("Contains(%q) = %v; want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxUncompressedBytes is the largest sitemap size allowed by the sitemaps protocol.
+const MaxUncompressedBytes = 50 * 1024 * 1024
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Document lists page URLs from a urlset and child sitemaps from a sitemap index.
+type Document struct {
+	URLs     []string
+	Sitemaps []string
+}
+
+type location struct {
+	Loc string `xml:"loc"`
+}
+
+type urlSet struct {
+	URLs []location `xml:"url"`
+}
+
+type sitemapIndex struct {
+	Sitemaps []location `xml:"sitemap"`
+}
+
+// Parse decodes a urlset or sitemapindex document; gzip-compressed bodies are detected by magic bytes.
+func Parse(body []byte) (Document, error) {
+	data, err := decompress(body)
+	if err != nil {
+		return Document{}, err
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return Document{}, fmt.Errorf("parse sitemap: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		return decodeRoot(decoder, start)
+	}
+}
+
+func decodeRoot(decoder *xml.Decoder, start xml.StartElement) (Document, error) {
+	switch start.Name.Local {
+	case "urlset":
+		var set urlSet
+		if err := decoder.DecodeElement(&set, &start); err != nil {
+			return Document{}, fmt.Errorf("parse urlset: %w", err)
+		}
+
+		return Document{URLs: locations(set.URLs)}, nil
+	case "sitemapindex":
+		var index sitemapIndex
+		if err := decoder.DecodeElement(&index, &start); err != nil {
+			return Document{}, fmt.Errorf("parse sitemap index: %w", err)
+		}
+
+		return Document{Sitemaps: locations(index.Sitemaps)}, nil
+	default:
+		return Document{}, fmt.Errorf("unexpected sitemap root element %q", start.Name.Local)
+	}
+}
+
+func decompress(body []byte) ([]byte, error) {
+	if !bytes.HasPrefix(body, gzipMagic) {
+		return body, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("gunzip sitemap: %w", err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(reader, MaxUncompressedBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("gunzip sitemap: %w", err)
+	}
+
+	if len(data) > MaxUncompressedBytes {
+		return nil, errors.New("gunzip sitemap: uncompressed size exceeds limit")
+	}
+
+	return data, nil
+}
+
+func locations(items []location) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		loc := strings.TrimSpace(item.Loc)
+		if loc == "" {
+			continue
+		}
+
+		out = append(out, loc)
+	}
+
+	return out
+}
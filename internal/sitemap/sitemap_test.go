@@ -0,0 +1,94 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"testing"
+)
+
+const urlSetXML = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc> https://example.com/a </loc><lastmod>2024-01-01</lastmod></url>
+  <url><loc>https://example.com/b</loc></url>
+  <url><loc></loc></url>
+</urlset>`
+
+const indexXML = `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://example.com/sitemap-1.xml</loc></sitemap>
+  <sitemap><loc>https://example.com/sitemap-2.xml.gz</loc></sitemap>
+</sitemapindex>`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		body    []byte
+		want    Document
+		wantErr bool
+	}{
+		{
+			name: "urlset",
+			body: []byte(urlSetXML),
+			want: Document{URLs: []string{"https://example.com/a", "https://example.com/b"}},
+		},
+		{
+			name: "sitemap index",
+			body: []byte(indexXML),
+			want: Document{Sitemaps: []string{"https://example.com/sitemap-1.xml", "https://example.com/sitemap-2.xml.gz"}},
+		},
+		{
+			name: "gzip urlset",
+			body: gzipBytes(t, []byte(urlSetXML)),
+			want: Document{URLs: []string{"https://example.com/a", "https://example.com/b"}},
+		},
+		{name: "unknown root", body: []byte(`<rss></rss>`), wantErr: true},
+		{name: "empty body", body: nil, wantErr: true},
+		{name: "broken gzip", body: []byte{0x1f, 0x8b, 0x00}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Parse(tt.body)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got.URLs, tt.want.URLs) && len(got.URLs)+len(tt.want.URLs) > 0 {
+				t.Fatalf("URLs = %v; want %v", got.URLs, tt.want.URLs)
+			}
+
+			if !reflect.DeepEqual(got.Sitemaps, tt.want.Sitemaps) && len(got.Sitemaps)+len(tt.want.Sitemaps) > 0 {
+				t.Fatalf("Sitemaps = %v; want %v", got.Sitemaps, tt.want.Sitemaps)
+			}
+		})
+	}
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		t.Fatalf("gzip write: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,135 @@
+package trap
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Rule names reported by Detector.Check.
+const (
+	RepeatedSegments = "repeated_segments"
+	URLLength        = "url_length"
+	QueryParams      = "query_params"
+	NumericVariants  = "numeric_variants"
+)
+
+// Default thresholds used for zero Rules fields.
+const (
+	DefaultMaxRepeatedSegments = 2
+	DefaultMaxURLLength        = 2000
+	DefaultMaxQueryParams      = 10
+	DefaultMaxNumericVariants  = 100
+)
+
+var digits = regexp.MustCompile(`[0-9]+`)
+
+// Rules sets the limits a URL must stay within.
+// MaxRepeatedSegments is how often one path segment may appear in a path, so
+// /a/b/a/b/a/b breaks a limit of 2. MaxURLLength counts bytes of the whole URL and
+// MaxQueryParams counts query parameters. MaxNumericVariants is how many URLs may differ
+// from each other only in their numbers, such as /calendar/2024/01 and /calendar/2024/02.
+type Rules struct {
+	MaxRepeatedSegments int
+	MaxURLLength        int
+	MaxQueryParams      int
+	MaxNumericVariants  int
+}
+
+// Detector flags URLs that look like crawler traps. It is not safe for concurrent use.
+type Detector struct {
+	rules    Rules
+	variants map[string]int
+}
+
+// NewDetector returns a Detector with the defaults filled in for zero fields.
+func NewDetector(rules Rules) *Detector {
+	if rules.MaxRepeatedSegments <= 0 {
+		rules.MaxRepeatedSegments = DefaultMaxRepeatedSegments
+	}
+
+	if rules.MaxURLLength <= 0 {
+		rules.MaxURLLength = DefaultMaxURLLength
+	}
+
+	if rules.MaxQueryParams <= 0 {
+		rules.MaxQueryParams = DefaultMaxQueryParams
+	}
+
+	if rules.MaxNumericVariants <= 0 {
+		rules.MaxNumericVariants = DefaultMaxNumericVariants
+	}
+
+	return &Detector{rules: rules, variants: map[string]int{}}
+}
+
+// Check returns the rule rawURL breaks, or "" when it looks safe to crawl, together with the
+// URL's pattern: the URL with every run of digits replaced by "{n}". Each URL should be checked
+// once; safe URLs count towards the numeric variants of their pattern.
+func (d *Detector) Check(rawURL string) (rule, pattern string) {
+	pattern = Pattern(rawURL)
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", pattern
+	}
+
+	switch {
+	case len(rawURL) > d.rules.MaxURLLength:
+		return URLLength, pattern
+	case maxRepeats(parsed.EscapedPath()) > d.rules.MaxRepeatedSegments:
+		return RepeatedSegments, pattern
+	case queryParams(parsed.RawQuery) > d.rules.MaxQueryParams:
+		return QueryParams, pattern
+	case d.variants[pattern] >= d.rules.MaxNumericVariants:
+		return NumericVariants, pattern
+	}
+
+	d.variants[pattern]++
+
+	return "", pattern
+}
+
+// Pattern replaces every run of digits in rawURL's path and query with "{n}"; the scheme
+// and host are kept as they are.
+func Pattern(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	pattern := parsed.Scheme + "://" + parsed.Host + digits.ReplaceAllString(parsed.EscapedPath(), "{n}")
+	if parsed.RawQuery != "" {
+		pattern += "?" + digits.ReplaceAllString(parsed.RawQuery, "{n}")
+	}
+
+	return pattern
+}
+
+func maxRepeats(path string) int {
+	counts := map[string]int{}
+	most := 0
+
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+
+		counts[segment]++
+		most = max(most, counts[segment])
+	}
+
+	return most
+}
+
+func queryParams(rawQuery string) int {
+	count := 0
+
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair != "" {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,82 @@
+package trap
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDetectorCheck(t *testing.T) {
+	t.Parallel()
+
+	longQuery := "https://example.com/search?q=" + strings.Repeat("x", DefaultMaxURLLength)
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "plain page", url: "https://example.com/docs/intro", want: ""},
+		{name: "segment twice", url: "https://example.com/a/b/a/b", want: ""},
+		{name: "repeated segments", url: "https://example.com/a/b/a/b/a/b", want: RepeatedSegments},
+		{name: "long url", url: longQuery, want: URLLength},
+		{name: "many params", url: "https://example.com/s?a=1&b=2&c=3&d=4&e=5&f=6&g=7&h=8&i=9&j=10&k=11", want: QueryParams},
+		{name: "ten params", url: "https://example.com/s?a=1&b=2&c=3&d=4&e=5&f=6&g=7&h=8&i=9&j=10", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got, _ := NewDetector(Rules{}).Check(tt.url); got != tt.want {
+				t.Fatalf("Check(%q) = %q; want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectorNumericVariants(t *testing.T) {
+	t.Parallel()
+
+	detector := NewDetector(Rules{MaxNumericVariants: 3})
+
+	for month := 1; month <= 5; month++ {
+		rawURL := fmt.Sprintf("https://example.com/calendar/2024/%02d", month)
+		rule, pattern := detector.Check(rawURL)
+
+		want := ""
+		if month > 3 {
+			want = NumericVariants
+		}
+
+		if rule != want {
+			t.Fatalf("Check(%q) = %q; want %q", rawURL, rule, want)
+		}
+
+		if pattern != "https://example.com/calendar/{n}/{n}" {
+			t.Fatalf("Check(%q) pattern = %q", rawURL, pattern)
+		}
+	}
+
+	if rule, _ := detector.Check("https://example.com/calendar"); rule != "" {
+		t.Fatalf("Check of another pattern = %q; want no rule", rule)
+	}
+}
+
+func TestPattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://example.com/page-12?sort=3&tag=go", want: "https://example.com/page-{n}?sort={n}&tag=go"},
+		{url: "https://example.com:8080/", want: "https://example.com:8080/"},
+	}
+
+	for _, tt := range tests {
+		if got := Pattern(tt.url); got != tt.want {
+			t.Fatalf("Pattern(%q) = %q; want %q", tt.url, got, tt.want)
+		}
+	}
+}
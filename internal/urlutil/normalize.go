@@ -0,0 +1,212 @@
+package urlutil
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// TrailingSlash says how a normalizer treats a "/" at the end of a path other than the root.
+type TrailingSlash string
+
+// Trailing slash modes understood by Normalizer.
+const (
+	// TrailingSlashIgnore keeps the slash in URLs but drops it from keys, so /docs and
+	// /docs/ are the same page and the form found first is fetched.
+	TrailingSlashIgnore TrailingSlash = "ignore"
+	// TrailingSlashKeep treats /docs and /docs/ as different URLs. It is the default.
+	TrailingSlashKeep TrailingSlash = "keep"
+	// TrailingSlashStrip removes the slash, so /docs/ is always fetched as /docs.
+	TrailingSlashStrip TrailingSlash = "strip"
+)
+
+// TrackingParams are the query parameters commonly added by analytics and ad links.
+var TrackingParams = []string{"utm_*", "fbclid", "gclid"}
+
+// ParseTrailingSlash validates a trailing slash mode; an empty name means TrailingSlashKeep.
+func ParseTrailingSlash(value string) (TrailingSlash, error) {
+	mode := TrailingSlash(strings.ToLower(strings.TrimSpace(value)))
+	switch mode {
+	case "":
+		return TrailingSlashKeep, nil
+	case TrailingSlashIgnore, TrailingSlashKeep, TrailingSlashStrip:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown trailing slash mode %q", value)
+	}
+}
+
+// Normalizer turns equivalent URLs into the same string. The fragment is always removed
+// and a "/" root path is always written as no path. Unless KeepCase is set, the scheme and
+// host are lowercased; unless KeepDefaultPort is set, :80 is dropped for http and :443
+// for https. StripParams removes query parameters by name, where a trailing "*" matches any
+// suffix, as in "utm_*". SortQuery orders query parameters by name, keeping the order of
+// repeated names. The zero Normalizer is ready to use.
+type Normalizer struct {
+	KeepCase        bool
+	KeepDefaultPort bool
+	StripParams     []string
+	SortQuery       bool
+	TrailingSlash   TrailingSlash
+}
+
+// Resolve resolves href against base and returns a normalized absolute HTTP(S) URL.
+// Links to a fragment of the same document, such as "#section", are not resolved.
+func (n Normalizer) Resolve(base *url.URL, href string) (string, bool) {
+	if strings.HasPrefix(strings.TrimSpace(href), "#") {
+		return "", false
+	}
+
+	resolved, ok := resolveHTTP(base, href)
+	if !ok {
+		return "", false
+	}
+
+	n.NormalizeURL(resolved)
+
+	return resolved.String(), true
+}
+
+// ResolveFragment resolves href against base like Resolve and also returns the decoded
+// fragment it points to. "#section" resolves to base itself. Links without a fragment, or
+// with an empty one, are not resolved.
+func (n Normalizer) ResolveFragment(base *url.URL, href string) (string, string, bool) {
+	resolved, ok := resolveHTTP(base, href)
+	if !ok || resolved.Fragment == "" {
+		return "", "", false
+	}
+
+	fragment := resolved.Fragment
+	n.NormalizeURL(resolved)
+
+	return resolved.String(), fragment, true
+}
+
+// Normalize returns the normalized form of rawURL, or rawURL itself if it cannot be parsed.
+func (n Normalizer) Normalize(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	n.NormalizeURL(parsed)
+
+	return parsed.String()
+}
+
+// Key returns the string used to tell whether two URLs are the same page. It is the
+// normalized URL, without the trailing slash unless TrailingSlash is TrailingSlashKeep.
+func (n Normalizer) Key(rawURL string) string {
+	if n.TrailingSlash == TrailingSlashKeep {
+		return n.Normalize(rawURL)
+	}
+
+	n.TrailingSlash = TrailingSlashStrip
+
+	return n.Normalize(rawURL)
+}
+
+// NormalizeURL normalizes u in place.
+func (n Normalizer) NormalizeURL(u *url.URL) {
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	if !n.KeepCase {
+		u.Scheme = strings.ToLower(u.Scheme)
+		u.Host = strings.ToLower(u.Host)
+	}
+
+	if !n.KeepDefaultPort && isDefaultPort(strings.ToLower(u.Scheme), u.Port()) {
+		u.Host = bracketIPv6(u.Hostname())
+	}
+
+	n.normalizePath(u)
+	n.normalizeQuery(u)
+}
+
+func (n Normalizer) normalizePath(u *url.URL) {
+	if u.Path == "/" {
+		u.Path = ""
+		u.RawPath = ""
+
+		return
+	}
+
+	if n.TrailingSlash == TrailingSlashStrip && u.Path != "" {
+		u.Path = strings.TrimRight(u.Path, "/")
+		u.RawPath = strings.TrimRight(u.RawPath, "/")
+	}
+}
+
+func (n Normalizer) normalizeQuery(u *url.URL) {
+	if u.RawQuery == "" {
+		u.ForceQuery = false
+
+		return
+	}
+
+	if len(n.StripParams) == 0 && !n.SortQuery {
+		return
+	}
+
+	pairs := make([]string, 0, strings.Count(u.RawQuery, "&")+1)
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		if pair != "" && !n.stripped(paramName(pair)) {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	if n.SortQuery {
+		sort.SliceStable(pairs, func(i, j int) bool {
+			return paramName(pairs[i]) < paramName(pairs[j])
+		})
+	}
+
+	u.RawQuery = strings.Join(pairs, "&")
+	u.ForceQuery = false
+}
+
+func (n Normalizer) stripped(name string) bool {
+	for _, pattern := range n.StripParams {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+
+			continue
+		}
+
+		if name == pattern {
+			return true
+		}
+	}
+
+	return false
+}
+
+func paramName(pair string) string {
+	name, _, _ := strings.Cut(pair, "=")
+	if unescaped, err := url.QueryUnescape(name); err == nil {
+		return unescaped
+	}
+
+	return name
+}
+
+func isDefaultPort(scheme, port string) bool {
+	return (scheme == "http" && port == "80") || (scheme == "https" && port == "443")
+}
+
+// bracketIPv6 writes a host without port the way it appears in a URL.
+func bracketIPv6(host string) string {
+	if strings.Contains(host, ":") {
+		return "[" + host + "]"
+	}
+
+	return host
+}
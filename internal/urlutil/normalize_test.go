@@ -0,0 +1,168 @@
+package urlutil
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestNormalizerNormalize(t *testing.T) {
+	t.Parallel()
+
+	tracking := Normalizer{StripParams: append(TrackingParams, "sessionid")}
+
+	tests := []struct {
+		name       string
+		normalizer Normalizer
+		raw        string
+		want       string
+	}{
+		{name: "root slash and default http port", raw: "HTTP://Example.COM:80/", want: "http://example.com"},
+		{name: "default https port", raw: "https://example.com:443/a", want: "https://example.com/a"},
+		{name: "non default port preserved", raw: "https://Example.com:8443/missing", want: "https://example.com:8443/missing"},
+		{name: "ipv6 default port", raw: "http://[::1]:80/a", want: "http://[::1]/a"},
+		{name: "fragment removed", raw: "https://example.com/missing#frag", want: "https://example.com/missing"},
+		{name: "empty query removed", raw: "https://example.com/a?", want: "https://example.com/a"},
+		{name: "invalid raw passthrough", raw: "://bad", want: "://bad"},
+		{name: "trailing slash kept by default", raw: "https://example.com/docs/", want: "https://example.com/docs/"},
+		{
+			name:       "trailing slash stripped",
+			normalizer: Normalizer{TrailingSlash: TrailingSlashStrip},
+			raw:        "https://example.com/docs/",
+			want:       "https://example.com/docs",
+		},
+		{
+			name:       "keep case and default port",
+			normalizer: Normalizer{KeepCase: true, KeepDefaultPort: true},
+			raw:        "https://Example.com:443/A",
+			want:       "https://Example.com:443/A",
+		},
+		{
+			name:       "tracking params stripped",
+			normalizer: tracking,
+			raw:        "https://example.com/a?utm_source=x&id=1&fbclid=y&utm_medium=z&gclid=w&sessionid=s",
+			want:       "https://example.com/a?id=1",
+		},
+		{
+			name:       "only tracking params",
+			normalizer: tracking,
+			raw:        "https://example.com/a?utm_source=x",
+			want:       "https://example.com/a",
+		},
+		{
+			name:       "prefix needs star",
+			normalizer: tracking,
+			raw:        "https://example.com/a?gclid_extra=1",
+			want:       "https://example.com/a?gclid_extra=1",
+		},
+		{
+			name:       "sorted query keeps repeated order",
+			normalizer: Normalizer{SortQuery: true},
+			raw:        "https://example.com/a?b=2&a=9&b=1",
+			want:       "https://example.com/a?a=9&b=2&b=1",
+		},
+		{name: "query order kept by default", raw: "https://example.com/a?b=2&a=1", want: "https://example.com/a?b=2&a=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.normalizer.Normalize(tt.raw); got != tt.want {
+				t.Fatalf("Normalize(%q) = %q; want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizerKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		mode TrailingSlash
+		a    string
+		b    string
+		same bool
+	}{
+		{name: "ignore", mode: TrailingSlashIgnore, a: "https://example.com/docs", b: "https://example.com/docs/", same: true},
+		{name: "default", a: "https://Example.com/docs/", b: "https://example.com/docs", same: true},
+		{name: "strip", mode: TrailingSlashStrip, a: "https://example.com/docs", b: "https://example.com/docs/", same: true},
+		{name: "keep", mode: TrailingSlashKeep, a: "https://example.com/docs", b: "https://example.com/docs/", same: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			normalizer := Normalizer{TrailingSlash: tt.mode}
+			if got := normalizer.Key(tt.a) == normalizer.Key(tt.b); got != tt.same {
+				t.Fatalf("Key(%q) == Key(%q) is %v; want %v", tt.a, tt.b, got, tt.same)
+			}
+		})
+	}
+}
+
+func TestNormalizerResolveStripsParams(t *testing.T) {
+	t.Parallel()
+
+	base, err := url.Parse("https://example.com/blog/")
+	if err != nil {
+		t.Fatalf("parse base url: %v", err)
+	}
+
+	got, ok := Normalizer{StripParams: TrackingParams, SortQuery: true}.Resolve(base, "post?utm_campaign=x&z=1&a=2")
+	if !ok || got != "https://example.com/blog/post?a=2&z=1" {
+		t.Fatalf("Resolve() = %q, %v", got, ok)
+	}
+}
+
+func TestNormalizerResolveFragment(t *testing.T) {
+	t.Parallel()
+
+	base, err := url.Parse("https://Example.com/docs/page")
+	if err != nil {
+		t.Fatalf("parse base url: %v", err)
+	}
+
+	tests := []struct {
+		href         string
+		wantTarget   string
+		wantFragment string
+		wantOK       bool
+	}{
+		{href: "#install", wantTarget: "https://example.com/docs/page", wantFragment: "install", wantOK: true},
+		{href: "other#a%20b", wantTarget: "https://example.com/docs/other", wantFragment: "a b", wantOK: true},
+		{href: "HTTPS://EXAMPLE.com:443/#top", wantTarget: "https://example.com", wantFragment: "top", wantOK: true},
+		{href: "other", wantOK: false},
+		{href: "other#", wantOK: false},
+		{href: "mailto:a@example.com#x", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.href, func(t *testing.T) {
+			t.Parallel()
+
+			target, fragment, ok := Normalizer{}.ResolveFragment(base, tt.href)
+			if ok != tt.wantOK || target != tt.wantTarget || fragment != tt.wantFragment {
+				t.Fatalf("ResolveFragment(%q) = %q, %q, %v; want %q, %q, %v",
+					tt.href, target, fragment, ok, tt.wantTarget, tt.wantFragment, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseTrailingSlash(t *testing.T) {
+	t.Parallel()
+
+	if mode, err := ParseTrailingSlash(""); err != nil || mode != TrailingSlashKeep {
+		t.Fatalf("ParseTrailingSlash(\"\") = %q, %v", mode, err)
+	}
+
+	if mode, err := ParseTrailingSlash("Strip"); err != nil || mode != TrailingSlashStrip {
+		t.Fatalf("ParseTrailingSlash(\"Strip\") = %q, %v", mode, err)
+	}
+
+	if _, err := ParseTrailingSlash("add"); err == nil {
+		t.Fatal("expected error for unknown mode")
+	}
+}
@@ -5,35 +5,29 @@ import (
 	"strings"
 )
 
-// Resolve resolves href against base and returns an absolute HTTP(S) URL.
-func Resolve(base *url.URL, href string) (string, bool) {
+func isSupportedScheme(scheme string) bool {
+	return scheme == "" || scheme == "http" || scheme == "https"
+}
+
+// resolveHTTP resolves href against base, keeping the fragment. It fails for empty links,
+// links that cannot be parsed and schemes other than HTTP(S).
+func resolveHTTP(base *url.URL, href string) (*url.URL, bool) {
 	trimmed := strings.TrimSpace(href)
-	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
-		return "", false
+	if trimmed == "" {
+		return nil, false
 	}
 
 	parsed, err := url.Parse(trimmed)
-	if err != nil {
-		return "", false
-	}
-
-	if !isSupportedScheme(parsed.Scheme) {
-		return "", false
+	if err != nil || !isSupportedScheme(parsed.Scheme) {
+		return nil, false
 	}
 
 	resolved := resolveReference(base, parsed)
 	if !isSupportedScheme(resolved.Scheme) {
-		return "", false
+		return nil, false
 	}
 
-	canonicalizeRootPath(resolved)
-	resolved.Fragment = ""
-
-	return resolved.String(), true
-}
-
-func isSupportedScheme(scheme string) bool {
-	return scheme == "" || scheme == "http" || scheme == "https"
+	return resolved, true
 }
 
 func resolveReference(base *url.URL, parsed *url.URL) *url.URL {
@@ -43,20 +37,3 @@ func resolveReference(base *url.URL, parsed *url.URL) *url.URL {
 
 	return parsed
 }
-
-func canonicalizeRootPath(u *url.URL) {
-	if u.Path == "/" {
-		u.Path = ""
-		u.RawPath = ""
-	}
-}
-
-// SameOrigin reports whether the URL has the same scheme and host (including port) as base.
-func SameOrigin(base *url.URL, raw string) bool {
-	parsed, err := url.Parse(raw)
-	if err != nil {
-		return false
-	}
-
-	return parsed.Scheme == base.Scheme && parsed.Host == base.Host
-}
@@ -33,7 +33,7 @@ func TestResolve(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			gotURL, gotOkay := Resolve(base, tt.href)
+			gotURL, gotOkay := Normalizer{}.Resolve(base, tt.href)
 			if gotOkay != tt.wantOkay {
 				t.Fatalf("unexpected ok flag: got %v want %v", gotOkay, tt.wantOkay)
 			}
@@ -44,36 +44,3 @@ func TestResolve(t *testing.T) {
 		})
 	}
 }
-
-func TestSameOrigin(t *testing.T) {
-	t.Parallel()
-
-	base, err := url.Parse("https://example.com:8443/root")
-	if err != nil {
-		t.Fatalf("parse base url: %v", err)
-	}
-
-	tests := []struct {
-		name string
-		raw  string
-		want bool
-	}{
-		{name: "same scheme host and port", raw: "https://example.com:8443/a", want: true},
-		{name: "different scheme", raw: "http://example.com:8443/a", want: false},
-		{name: "different host", raw: "https://other.com:8443/a", want: false},
-		{name: "different port", raw: "https://example.com/a", want: false},
-		{name: "invalid url", raw: "http://[::1", want: false},
-		{name: "relative url", raw: "/local", want: false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-
-			got := SameOrigin(base, tt.raw)
-			if got != tt.want {
-				t.Fatalf("unexpected same-origin result: got %v want %v", got, tt.want)
-			}
-		})
-	}
-}